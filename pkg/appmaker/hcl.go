@@ -0,0 +1,43 @@
+package appmaker
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/errordeveloper/kubegen/pkg/util"
+)
+
+type appGroup struct {
+	Apps []SimpleApp `hcl:"app"`
+}
+
+// NewAppFromHCL parses `app "<name>" { ... }` blocks and returns a list with
+// a Deployment of each of the apps, followed by a Service if the app exposes
+// a port and a HorizontalPodAutoscaler if it has an `autoscale` block
+func NewAppFromHCL(data []byte) (*metav1.List, error) {
+	group := &appGroup{}
+	if err := util.NewFromHCL(group, data); err != nil {
+		return nil, err
+	}
+
+	list := &metav1.List{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "List",
+			APIVersion: "v1",
+		},
+	}
+
+	for n := range group.Apps {
+		app := &group.Apps[n]
+		if app.Image == "" {
+			return nil, fmt.Errorf("kubegen/appmaker: invalid app %q – no image", app.Name)
+		}
+		if app.Autoscale != nil && app.Autoscale.MaxReplicas == 0 {
+			return nil, fmt.Errorf("kubegen/appmaker: invalid app %q – autoscale block has no max_replicas", app.Name)
+		}
+		list.Items = append(list.Items, app.Build().Items...)
+	}
+
+	return list, nil
+}
@@ -0,0 +1,105 @@
+package appmaker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestNewAppFromHCL(t *testing.T) {
+	assert := assert.New(t)
+
+	manifest := `
+app "web" {
+  namespace = "prod"
+  image = "nginx:1.13"
+  port = 80
+  replicas = 2
+  env {
+    MODE = "production"
+    DEBUG = "false"
+  }
+  autoscale {
+    min_replicas = 2
+    max_replicas = 10
+    target_cpu = 75
+  }
+}
+
+app "worker" {
+  image = "worker:latest"
+}
+`
+
+	list, err := NewAppFromHCL([]byte(manifest))
+	if !assert.NoError(err) || !assert.Len(list.Items, 4) {
+		return
+	}
+
+	labels := map[string]string{"name": "web"}
+
+	deployment, ok := list.Items[0].Object.(*appsv1.Deployment)
+	if assert.True(ok) {
+		assert.Equal("web", deployment.Name)
+		assert.Equal("prod", deployment.Namespace)
+		assert.Equal(int32(2), *deployment.Spec.Replicas)
+		assert.Equal(labels, deployment.Spec.Selector.MatchLabels)
+		assert.Equal(labels, deployment.Spec.Template.Labels)
+		if assert.Len(deployment.Spec.Template.Spec.Containers, 1) {
+			container := deployment.Spec.Template.Spec.Containers[0]
+			assert.Equal("nginx:1.13", container.Image)
+			assert.Equal([]corev1.EnvVar{{Name: "DEBUG", Value: "false"}, {Name: "MODE", Value: "production"}}, container.Env)
+			assert.Equal([]corev1.ContainerPort{{ContainerPort: 80}}, container.Ports)
+		}
+	}
+
+	service, ok := list.Items[1].Object.(*corev1.Service)
+	if assert.True(ok) {
+		assert.Equal("web", service.Name)
+		assert.Equal("prod", service.Namespace)
+		assert.Equal(labels, service.Spec.Selector)
+		assert.Equal([]corev1.ServicePort{{Port: 80, TargetPort: intstr.FromInt(80)}}, service.Spec.Ports)
+	}
+
+	hpa, ok := list.Items[2].Object.(*autoscalingv1.HorizontalPodAutoscaler)
+	if assert.True(ok) {
+		assert.Equal("web", hpa.Name)
+		assert.Equal("prod", hpa.Namespace)
+		assert.Equal(autoscalingv1.CrossVersionObjectReference{Kind: "Deployment", Name: "web", APIVersion: "apps/v1"}, hpa.Spec.ScaleTargetRef)
+		assert.Equal(int32(2), *hpa.Spec.MinReplicas)
+		assert.Equal(int32(10), hpa.Spec.MaxReplicas)
+		assert.Equal(int32(75), *hpa.Spec.TargetCPUUtilizationPercentage)
+	}
+
+	// an app that doesn't listen on a port only gets a Deployment
+	worker, ok := list.Items[3].Object.(*appsv1.Deployment)
+	if assert.True(ok) {
+		assert.Equal("worker", worker.Name)
+		assert.Equal(int32(1), *worker.Spec.Replicas)
+	}
+}
+
+func TestNewAppFromHCLErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, manifest := range []string{
+		`app "web" { port = 80 }`,
+		`app "web" {
+  image = "nginx"
+  autoscale {
+    min_replicas = 2
+  }
+}`,
+	} {
+		_, err := NewAppFromHCL([]byte(manifest))
+		if assert.Error(err, manifest) {
+			assert.Contains(err.Error(), "kubegen/appmaker: ")
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package appmaker
+
+import (
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// This package provides a higher-level abstraction for the most
+// common kinds of apps, it generates native objects directly.
+
+// SimpleApp is a single-container app, which gets a Deployment
+// and, if it listens on a port, a Service in front of it
+type SimpleApp struct {
+	Name      string            `yaml:"name" hcl:",key"`
+	Namespace string            `yaml:"namespace,omitempty" hcl:"namespace"`
+	Image     string            `yaml:"image" hcl:"image"`
+	Port      int32             `yaml:"port,omitempty" hcl:"port"`
+	Replicas  int32             `yaml:"replicas,omitempty" hcl:"replicas"`
+	Env       map[string]string `yaml:"env,omitempty" hcl:"env"`
+	// Expose is the same as Port, it's used when Port is not set
+	Expose int32 `yaml:"expose,omitempty" hcl:"expose"`
+	// Autoscale adds a HorizontalPodAutoscaler for the Deployment
+	Autoscale *Autoscale `yaml:"autoscale,omitempty" hcl:"autoscale"`
+}
+
+// Autoscale describes how the app gets scaled between MinReplicas and
+// MaxReplicas, based on average CPU utilization of its pods
+type Autoscale struct {
+	MinReplicas int32 `yaml:"minReplicas,omitempty" hcl:"min_replicas"`
+	MaxReplicas int32 `yaml:"maxReplicas" hcl:"max_replicas"`
+	TargetCPU   int32 `yaml:"targetCPU,omitempty" hcl:"target_cpu"`
+}
+
+func (i *SimpleApp) port() int32 {
+	if i.Port != 0 {
+		return i.Port
+	}
+	return i.Expose
+}
+
+func (i *SimpleApp) labels() map[string]string {
+	return map[string]string{"name": i.Name}
+}
+
+func (i *SimpleApp) meta() metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      i.Name,
+		Namespace: i.Namespace,
+		Labels:    i.labels(),
+	}
+}
+
+func (i *SimpleApp) container() corev1.Container {
+	container := corev1.Container{
+		Name:  i.Name,
+		Image: i.Image,
+	}
+
+	keys := []string{}
+	for k := range i.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		container.Env = append(container.Env, corev1.EnvVar{Name: k, Value: i.Env[k]})
+	}
+
+	if port := i.port(); port != 0 {
+		container.Ports = []corev1.ContainerPort{{ContainerPort: port}}
+	}
+
+	return container
+}
+
+// Deployment returns the Deployment that runs the app
+func (i *SimpleApp) Deployment() *appsv1.Deployment {
+	replicas := i.Replicas
+	if replicas == 0 {
+		replicas = 1
+	}
+
+	return &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Deployment",
+			APIVersion: "apps/v1",
+		},
+		ObjectMeta: i.meta(),
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: i.labels()},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: i.labels()},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{i.container()},
+				},
+			},
+		},
+	}
+}
+
+// Service returns the Service that exposes the app, it
+// returns nil if the app doesn't listen on any port
+func (i *SimpleApp) Service() *corev1.Service {
+	port := i.port()
+	if port == 0 {
+		return nil
+	}
+
+	return &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: "v1",
+		},
+		ObjectMeta: i.meta(),
+		Spec: corev1.ServiceSpec{
+			Selector: i.labels(),
+			Ports: []corev1.ServicePort{{
+				Port:       port,
+				TargetPort: intstr.FromInt(int(port)),
+			}},
+		},
+	}
+}
+
+// HorizontalPodAutoscaler returns the autoscaler of the app's Deployment,
+// it returns nil if autoscaling is not enabled
+func (i *SimpleApp) HorizontalPodAutoscaler() *autoscalingv1.HorizontalPodAutoscaler {
+	if i.Autoscale == nil {
+		return nil
+	}
+
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "HorizontalPodAutoscaler",
+			APIVersion: "autoscaling/v1",
+		},
+		ObjectMeta: i.meta(),
+		Spec: autoscalingv1.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{
+				Kind:       "Deployment",
+				Name:       i.Name,
+				APIVersion: "apps/v1",
+			},
+			MaxReplicas: i.Autoscale.MaxReplicas,
+		},
+	}
+	if i.Autoscale.MinReplicas != 0 {
+		minReplicas := i.Autoscale.MinReplicas
+		hpa.Spec.MinReplicas = &minReplicas
+	}
+	if i.Autoscale.TargetCPU != 0 {
+		targetCPU := i.Autoscale.TargetCPU
+		hpa.Spec.TargetCPUUtilizationPercentage = &targetCPU
+	}
+	return hpa
+}
+
+// Build returns a list with all objects of the app
+func (i *SimpleApp) Build() *metav1.List {
+	list := &metav1.List{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "List",
+			APIVersion: "v1",
+		},
+	}
+
+	list.Items = append(list.Items, runtime.RawExtension{Object: i.Deployment()})
+	if service := i.Service(); service != nil {
+		list.Items = append(list.Items, runtime.RawExtension{Object: service})
+	}
+	if hpa := i.HorizontalPodAutoscaler(); hpa != nil {
+		list.Items = append(list.Items, runtime.RawExtension{Object: hpa})
+	}
+
+	return list
+}
@@ -515,7 +515,8 @@ func (m *Module) EncodeGroupsToJSON(instance ModuleInstance) (map[ManifestPath][
 			continue
 		}
 
-		output[manifestPath] = append(data, byte('\n'))
+		// encoded output already ends with a newline
+		output[manifestPath] = data
 	}
 
 	return output, nil
@@ -0,0 +1,68 @@
+package resources
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/errordeveloper/kubegen/pkg/util"
+)
+
+func TestConfigMapHeredoc(t *testing.T) {
+	assert := assert.New(t)
+
+	appConf := "server {\n  listen 80;\n  root /srv/www;\n}\n"
+
+	manifests := map[string]string{
+		"heredoc": `
+configmap "app" {
+  data {
+    "app.conf" = <<EOF
+server {
+  listen 80;
+  root /srv/www;
+}
+EOF
+    "mode" = "production"
+  }
+}
+`,
+		"indented heredoc": `
+configmap "app" {
+  data {
+    "app.conf" = <<-EOF
+      server {
+        listen 80;
+        root /srv/www;
+      }
+      EOF
+    "mode" = "production"
+  }
+}
+`,
+	}
+
+	for name, manifest := range manifests {
+		for lineEnding, manifest := range map[string]string{"LF": manifest, "CRLF": strings.Replace(manifest, "\n", "\r\n", -1)} {
+			t.Run(name+" with "+lineEnding, func(t *testing.T) {
+				group := &Group{}
+				if err := util.NewFromHCL(group, []byte(manifest)); err != nil {
+					t.Fatal(err)
+				}
+				if !assert.Len(group.ConfigMaps, 1) {
+					return
+				}
+
+				configMap, err := group.ConfigMaps[0].Convert(group)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				assert.Equal("app", configMap.Name)
+				assert.Equal(appConf, configMap.Data["app.conf"])
+				assert.Equal("production", configMap.Data["mode"])
+			})
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package resources
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/errordeveloper/kubegen/pkg/util"
+)
+
+// ConvertDir finds HCL manifests (".hcl" or ".kg") in srcDir, and writes
+// all of the objects each of them defines into dstDir, using the same
+// filenames that util.DumpListToFiles uses; subdirectories are not searched
+func ConvertDir(srcDir, dstDir, contentType string) ([]string, error) {
+	entries, err := ioutil.ReadDir(srcDir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading directory %q – %v", srcDir, err)
+	}
+
+	if err := os.MkdirAll(dstDir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating output directory %q – %v", dstDir, err)
+	}
+
+	filenames := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		switch filepath.Ext(entry.Name()) {
+		case ".hcl", ".kg":
+		default:
+			continue
+		}
+
+		sourcePath := filepath.Join(srcDir, entry.Name())
+		data, err := ioutil.ReadFile(sourcePath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading manifest %q – %v", sourcePath, err)
+		}
+
+		group := &Group{}
+		if err := util.NewFromHCL(group, data); err != nil {
+			return nil, fmt.Errorf("error loading manifest %q – %v", sourcePath, err)
+		}
+
+		list, err := group.MakeList()
+		if err != nil {
+			return nil, fmt.Errorf("error converting manifest %q – %v", sourcePath, err)
+		}
+
+		written, err := util.DumpListToFilesWithOptions(list, contentType, util.DumpOptions{
+			Dir:    dstDir,
+			Source: sourcePath,
+		})
+		if err != nil {
+			return nil, err
+		}
+		filenames = append(filenames, written...)
+	}
+
+	return filenames, nil
+}
@@ -0,0 +1,30 @@
+package util
+
+import (
+	"strings"
+)
+
+// groupsByVersionAndKind maps "version/kind" to the groups that define such kind
+// at such version; the core group is recorded as an empty string
+func groupsByVersionAndKind() map[string][]string {
+	groups := make(map[string][]string)
+	for gvk := range typesScheme.AllKnownTypes() {
+		key := gvk.Version + "/" + gvk.Kind
+		groups[key] = append(groups[key], gvk.Group)
+	}
+	return groups
+}
+
+// qualifyAPIVersion expands an abbreviated apiVersion (one without a group) of an
+// object of a non-core kind to the full group/version form, when there is exactly
+// one group that defines the kind at that version, and the core group is not one
+func qualifyAPIVersion(groups map[string][]string, apiVersion, kind string) string {
+	if apiVersion == "" || strings.Contains(apiVersion, "/") {
+		return apiVersion
+	}
+	candidates := groups[apiVersion+"/"+kind]
+	if len(candidates) != 1 || candidates[0] == "" {
+		return apiVersion
+	}
+	return candidates[0] + "/" + apiVersion
+}
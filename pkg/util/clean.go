@@ -0,0 +1,83 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// generatedFileMarker is the first line of DefaultHeaderTemplate
+var generatedFileMarker = []byte("# generated by kubegen\n")
+
+func isGeneratedFile(filename string) (bool, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	head := make([]byte, len(generatedFileMarker))
+	if _, err := io.ReadFull(f, head); err != nil {
+		// files shorter than the marker are not generated
+		return false, nil
+	}
+	return bytes.Equal(head, generatedFileMarker), nil
+}
+
+// DumpListToFilesClean is like DumpListToFiles, but it also deletes any files in dir that
+// have been previously generated and are no longer part of the list; generated files are
+// recognised by the header, so only YAML files with the default header get cleaned up
+func DumpListToFilesClean(list *metav1.List, contentType, dir string) ([]string, []string, error) {
+	written, err := DumpListToFilesWithOptions(list, contentType, DumpOptions{Dir: dir})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	current := make(map[string]bool, len(written))
+	for _, filename := range written {
+		current[path.Clean(filename)] = true
+	}
+
+	listDir := dir
+	if listDir == "" {
+		listDir = "."
+	}
+	entries, err := ioutil.ReadDir(listDir)
+	if err != nil {
+		return written, nil, fmt.Errorf("kubegen/util: error reading output directory %q – %v", listDir, err)
+	}
+
+	deleted := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		switch path.Ext(entry.Name()) {
+		case ".yaml", ".yml":
+		default:
+			continue
+		}
+		filename := path.Join(dir, entry.Name())
+		if current[path.Clean(filename)] {
+			continue
+		}
+		generated, err := isGeneratedFile(filename)
+		if err != nil {
+			return written, deleted, fmt.Errorf("kubegen/util: error reading file %q – %v", filename, err)
+		}
+		if !generated {
+			continue
+		}
+		if err := os.Remove(filename); err != nil {
+			return written, deleted, fmt.Errorf("kubegen/util: error deleting stale file %q – %v", filename, err)
+		}
+		deleted = append(deleted, filename)
+	}
+
+	return written, deleted, nil
+}
@@ -2,10 +2,167 @@ package util
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/ghodss/yaml"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
+// CleanupOptions control the cleanup pass that removes empty
+// and meaningless fields from encoded objects
+type CleanupOptions struct {
+	// Only limits the built-in cleanup rules to the fields that match
+	// any of the given paths (or are nested within those), e.g.
+	// `spec.template.spec.containers[*].resources`, a path is relative
+	// to each object, so it applies to every item of a list as well
+	Only []string
+	// KeepEmpty lists, per kind, paths of empty blocks that the built-in
+	// cleanup rules should leave in place, e.g. `spec.strategy` for
+	// Deployment; the same paths are still removed from other kinds
+	KeepEmpty map[string][]string
+	// StripServerFields removes status and ServerManagedMetadata from every object,
+	// which turns the output of `kubectl get -o yaml` into a committable manifest
+	StripServerFields bool
+}
+
+type cleaner struct {
+	only  []fieldPathPattern
+	strip []fieldPathPattern
+	// keepEmpty is KeepEmpty parsed, keep is what applies to current item
+	keepEmpty map[string][]fieldPathPattern
+	keep      []fieldPathPattern
+
+	apiVersion, kind string
+	generatedAt      string
+	defaultNamespace string
+	minify           bool
+	// groups is set when abbreviated apiVersion should be qualified
+	groups map[string][]string
+}
+
+// ServerManagedMetadata lists paths of metadata fields that the API server sets
+// on live objects, they can be given as EncodeOptions.StripKeys to turn objects
+// dumped from a cluster back into manifests
+var ServerManagedMetadata = []string{
+	"metadata.uid",
+	"metadata.resourceVersion",
+	"metadata.generation",
+	"metadata.selfLink",
+	"metadata.creationTimestamp",
+	"metadata.managedFields",
+	"metadata.finalizers",
+	"metadata.deletionTimestamp",
+	"metadata.deletionGracePeriodSeconds",
+}
+
+// GeneratedAtAnnotation is set when EncodeOptions.AnnotateGeneratedAt is enabled
+const GeneratedAtAnnotation = "kubegen.io/generated-at"
+
+// now is a variable, so that tests can fake it
+var now = time.Now
+
+func newCleaner(opts EncodeOptions) (*cleaner, error) {
+	only, err := parseFieldPathPatterns(opts.Cleanup.Only)
+	if err != nil {
+		return nil, err
+	}
+	stripKeys := opts.StripKeys
+	if opts.StripStatus || opts.Cleanup.StripServerFields {
+		stripKeys = append([]string{"status"}, stripKeys...)
+	}
+	if opts.Cleanup.StripServerFields {
+		stripKeys = append(stripKeys, ServerManagedMetadata...)
+	}
+	strip, err := parseFieldPathPatterns(stripKeys)
+	if err != nil {
+		return nil, err
+	}
+	keepEmpty := make(map[string][]fieldPathPattern, len(opts.Cleanup.KeepEmpty))
+	for kind, paths := range opts.Cleanup.KeepEmpty {
+		keep, err := parseFieldPathPatterns(paths)
+		if err != nil {
+			return nil, err
+		}
+		keepEmpty[kind] = keep
+	}
+	c := &cleaner{
+		only:       only,
+		strip:      strip,
+		keepEmpty:  keepEmpty,
+		apiVersion: opts.APIVersion,
+		kind:       opts.Kind,
+		minify:     opts.Minify,
+	}
+	if opts.QualifyAPIVersion {
+		c.groups = groupsByVersionAndKind()
+	}
+	if opts.DefaultNamespace != "" {
+		c.defaultNamespace = opts.DefaultNamespace
+	}
+	if opts.AnnotateGeneratedAt {
+		c.generatedAt = now().UTC().Format(time.RFC3339)
+	}
+	return c, nil
+}
+
+func (c *cleaner) touches(path fieldPath) bool {
+	if len(c.only) == 0 {
+		return true
+	}
+	for _, pattern := range c.only {
+		if pattern.matchesPrefixOf(path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *cleaner) strips(path fieldPath) bool {
+	for _, pattern := range c.strip {
+		if pattern.matches(path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *cleaner) keeps(path fieldPath) bool {
+	for _, pattern := range c.keep {
+		if pattern.matches(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripKeys removes all keys matching any of the strip patterns, and
+// any maps which end up empty as a result of removing those keys
+func (c *cleaner) stripKeys(obj map[string]interface{}, at fieldPath) {
+	for key, value := range obj {
+		path := at.key(key)
+		if c.strips(path) {
+			delete(obj, key)
+			continue
+		}
+		switch value := value.(type) {
+		case map[string]interface{}:
+			if len(value) != 0 {
+				c.stripKeys(value, path)
+				if len(value) == 0 {
+					delete(obj, key)
+				}
+			}
+		case []interface{}:
+			for n, x := range value {
+				if x, ok := x.(map[string]interface{}); ok {
+					c.stripKeys(x, path.index(n))
+				}
+			}
+		}
+	}
+}
+
 func toNonEmptyMap(obj interface{}) (map[string]interface{}, bool) {
 	if v, ok := obj.(map[string]interface{}); ok && len(v) != 0 {
 		return v, ok
@@ -22,110 +179,291 @@ func getMap(obj map[string]interface{}, key string) (map[string]interface{}, boo
 	return nil, false
 }
 
-func rangeOverNonEmptyMapsInSlice(obj map[string]interface{}, key string, iter func(map[string]interface{})) {
+func rangeOverNonEmptyMapsInSlice(obj map[string]interface{}, at fieldPath, key string, iter func(map[string]interface{}, fieldPath)) {
 	if v, ok := obj[key]; ok {
 		if v, ok := v.([]interface{}); ok && len(v) != 0 {
-			for _, x := range v {
+			for n, x := range v {
 				if x, ok := x.(map[string]interface{}); ok && len(x) != 0 {
-					iter(x)
+					iter(x, at.key(key).index(n))
 				}
 			}
 		}
 	}
 }
 
-func deleteKeyIfValueIsNil(obj map[string]interface{}, key string) {
+func (c *cleaner) deleteKeyIfValueIsNil(obj map[string]interface{}, at fieldPath, key string) {
+	if v, ok := obj[key]; ok {
+		if v == nil && c.touches(at.key(key)) {
+			delete(obj, key)
+		}
+	}
+}
+
+func (c *cleaner) deleteKeyIfValueIsEmptyString(obj map[string]interface{}, at fieldPath, key string) {
 	if v, ok := obj[key]; ok {
-		if v == nil {
+		if v, ok := v.(string); ok && v == "" && c.touches(at.key(key)) {
 			delete(obj, key)
 		}
 	}
 }
 
-func deleteSubKeyIfValueIsNil(obj map[string]interface{}, k0, k1 string) {
+func (c *cleaner) deleteSubKeyIfValueIsNil(obj map[string]interface{}, at fieldPath, k0, k1 string) {
 	if v, ok := getMap(obj, k0); ok {
-		deleteKeyIfValueIsNil(v, k1)
+		c.deleteKeyIfValueIsNil(v, at.key(k0), k1)
 	}
-	deleteKeyIfValueIsEmptyMap(obj, k0)
+	c.deleteKeyIfValueIsEmptyMap(obj, at, k0)
 }
 
-func deleteKeyIfValueIsEmptyMap(obj map[string]interface{}, key string) {
+func (c *cleaner) deleteKeyIfValueIsEmptyMap(obj map[string]interface{}, at fieldPath, key string) {
 	if v, ok := obj[key]; ok {
-		if v, ok := v.(map[string]interface{}); ok && len(v) == 0 {
+		if v, ok := v.(map[string]interface{}); ok && len(v) == 0 && c.touches(at.key(key)) && !c.keeps(at.key(key)) {
 			delete(obj, key)
 		}
 	}
 }
 
-func deleteSubKeyIfValueIsEmptyMap(obj map[string]interface{}, k0, k1 string) {
+func (c *cleaner) deleteSubKeyIfValueIsEmptyMap(obj map[string]interface{}, at fieldPath, k0, k1 string) {
 	if v, ok := getMap(obj, k0); ok {
-		deleteKeyIfValueIsEmptyMap(v, k1)
+		c.deleteKeyIfValueIsEmptyMap(v, at.key(k0), k1)
 	}
-	deleteKeyIfValueIsEmptyMap(obj, k0)
+	c.deleteKeyIfValueIsEmptyMap(obj, at, k0)
 }
 
-func cleanupInnerSpec(item map[string]interface{}) {
-	deleteSubKeyIfValueIsNil(item, "metadata", "creationTimestamp")
-	deleteSubKeyIfValueIsEmptyMap(item, "status", "loadBalancer")
+func (c *cleaner) cleanupInnerSpec(item map[string]interface{}) {
+	at := fieldPath{}
+
+	kind, _ := item["kind"].(string)
+	c.keep = c.keepEmpty[kind]
+
+	if len(c.strip) != 0 {
+		c.stripKeys(item, at)
+	}
+
+	c.deleteSubKeyIfValueIsNil(item, at, "metadata", "creationTimestamp")
+	c.deleteSubKeyIfValueIsEmptyMap(item, at, "status", "loadBalancer")
+
+	if metadata, ok := getMap(item, "metadata"); ok {
+		// owner references of generated objects usually have no UID
+		rangeOverNonEmptyMapsInSlice(metadata, at.key("metadata"), "ownerReferences", func(ref map[string]interface{}, at fieldPath) {
+			c.deleteKeyIfValueIsEmptyString(ref, at, "uid")
+		})
+	}
 
-	deleteSubKeyIfValueIsEmptyMap(item, "spec", "strategy")
-	deleteSubKeyIfValueIsEmptyMap(item, "spec", "updateStrategy")
+	// EndpointSlice has endpoints at the top-level
+	rangeOverNonEmptyMapsInSlice(item, at, "endpoints", func(endpoint map[string]interface{}, at fieldPath) {
+		c.deleteKeyIfValueIsEmptyMap(endpoint, at, "conditions")
+	})
+
+	c.deleteSubKeyIfValueIsEmptyMap(item, at, "spec", "strategy")
+	c.deleteSubKeyIfValueIsEmptyMap(item, at, "spec", "updateStrategy")
 
 	if spec, ok := getMap(item, "spec"); ok {
+		at := at.key("spec")
 		if template, ok := getMap(spec, "template"); ok {
+			at := at.key("template")
 			if spec, ok := getMap(template, "spec"); ok {
-				rangeOverNonEmptyMapsInSlice(spec, "initContainers", func(container map[string]interface{}) {
-					deleteKeyIfValueIsEmptyMap(container, "resources")
-					deleteKeyIfValueIsEmptyMap(container, "securityContext")
+				at := at.key("spec")
+				rangeOverNonEmptyMapsInSlice(spec, at, "initContainers", func(container map[string]interface{}, at fieldPath) {
+					c.deleteKeyIfValueIsEmptyMap(container, at, "resources")
+					c.deleteKeyIfValueIsEmptyMap(container, at, "securityContext")
 				})
-				rangeOverNonEmptyMapsInSlice(spec, "containers", func(container map[string]interface{}) {
-					deleteKeyIfValueIsEmptyMap(container, "resources")
-					deleteKeyIfValueIsEmptyMap(container, "securityContext")
+				rangeOverNonEmptyMapsInSlice(spec, at, "containers", func(container map[string]interface{}, at fieldPath) {
+					c.deleteKeyIfValueIsEmptyMap(container, at, "resources")
+					c.deleteKeyIfValueIsEmptyMap(container, at, "securityContext")
 				})
+				c.deleteKeyIfValueIsEmptyMap(spec, at, "securityContext")
 			}
 
-			deleteSubKeyIfValueIsNil(template, "metadata", "creationTimestamp")
+			c.deleteSubKeyIfValueIsNil(template, at, "metadata", "creationTimestamp")
+		}
+
+		rangeOverNonEmptyMapsInSlice(spec, at, "volumeClaimTemplates", func(claim map[string]interface{}, at fieldPath) {
+			c.deleteSubKeyIfValueIsNil(claim, at, "metadata", "creationTimestamp")
+			c.deleteKeyIfValueIsEmptyMap(claim, at, "status")
+		})
+	}
+}
+
+func (c *cleaner) qualifyAPIVersion(obj map[string]interface{}) {
+	apiVersion, _ := obj["apiVersion"].(string)
+	kind, _ := obj["kind"].(string)
+	if qualified := qualifyAPIVersion(c.groups, apiVersion, kind); qualified != apiVersion {
+		obj["apiVersion"] = qualified
+	}
+}
+
+// meaningfulEmptyValues are keys that mean something even when the value is empty,
+// e.g. a volume with `emptyDir: {}` or a NetworkPolicy with `podSelector: {}`
+var meaningfulEmptyValues = map[string]bool{
+	"emptyDir":          true,
+	"podSelector":       true,
+	"namespaceSelector": true,
+}
+
+func isEmptyValue(value interface{}) bool {
+	switch value := value.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		return len(value) == 0
+	case []interface{}:
+		return len(value) == 0
+	}
+	return false
+}
+
+// pruneEmptyValues removes empty values from obj recursively, maps that end
+// up empty are removed as well; items of lists are never removed, as that
+// would shift positions of other items, only their contents are pruned
+func pruneEmptyValues(obj map[string]interface{}) {
+	for key, value := range obj {
+		switch value := value.(type) {
+		case map[string]interface{}:
+			pruneEmptyValues(value)
+		case []interface{}:
+			for _, x := range value {
+				if x, ok := x.(map[string]interface{}); ok {
+					pruneEmptyValues(x)
+				}
+			}
+		}
+		if isEmptyValue(value) && !meaningfulEmptyValues[key] {
+			delete(obj, key)
 		}
 	}
 }
 
-func doCleanup(obj map[string]interface{}) {
-	cleanupInnerSpec(obj)
-	rangeOverNonEmptyMapsInSlice(obj, "items", func(item map[string]interface{}) {
+func (c *cleaner) overrideTypeMeta(obj map[string]interface{}) {
+	if c.groups != nil {
+		c.qualifyAPIVersion(obj)
+	}
+	if c.apiVersion != "" {
+		obj["apiVersion"] = c.apiVersion
+	}
+	if c.kind != "" {
+		obj["kind"] = c.kind
+	}
+}
+
+func (c *cleaner) annotateGeneratedAt(item map[string]interface{}) {
+	metadata, ok := item["metadata"].(map[string]interface{})
+	if !ok {
+		metadata = make(map[string]interface{})
+		item["metadata"] = metadata
+	}
+	annotations, ok := metadata["annotations"].(map[string]interface{})
+	if !ok {
+		annotations = make(map[string]interface{})
+		metadata["annotations"] = annotations
+	}
+	annotations[GeneratedAtAnnotation] = c.generatedAt
+}
+
+// setDefaultNamespace sets namespace of a namespaced object that has none
+func (c *cleaner) setDefaultNamespace(item map[string]interface{}) {
+	apiVersion, _ := item["apiVersion"].(string)
+	kind, _ := item["kind"].(string)
+	if !isNamespacedKind(schema.FromAPIVersionAndKind(apiVersion, kind)) {
+		return
+	}
+	metadata, ok := item["metadata"].(map[string]interface{})
+	if !ok {
+		metadata = make(map[string]interface{})
+		item["metadata"] = metadata
+	}
+	if namespace, _ := metadata["namespace"].(string); namespace == "" {
+		metadata["namespace"] = c.defaultNamespace
+	}
+}
+
+func (c *cleaner) doCleanup(obj map[string]interface{}) {
+	c.overrideTypeMeta(obj)
+	c.cleanupInnerSpec(obj)
+	_, isList := obj["items"]
+	if c.generatedAt != "" && !isList {
+		c.annotateGeneratedAt(obj)
+	}
+	if c.defaultNamespace != "" && !isList {
+		c.setDefaultNamespace(obj)
+	}
+	rangeOverNonEmptyMapsInSlice(obj, fieldPath{}, "items", func(item map[string]interface{}, _ fieldPath) {
 		if item, ok := toNonEmptyMap(item); ok {
-			cleanupInnerSpec(item)
+			c.cleanupInnerSpec(item)
+			if c.groups != nil {
+				c.qualifyAPIVersion(item)
+			}
+			if c.generatedAt != "" {
+				c.annotateGeneratedAt(item)
+			}
+			if c.defaultNamespace != "" {
+				c.setDefaultNamespace(item)
+			}
 		}
 	})
+	if c.minify {
+		pruneEmptyValues(obj)
+	}
 }
 
-func cleanup(contentType string, input []byte, pretty bool) ([]byte, error) {
+func cleanup(contentType string, input []byte, opts EncodeOptions) ([]byte, error) {
 	obj := make(map[string]interface{})
 	var (
 		output []byte
 		err    error
 	)
+
+	c, err := newCleaner(opts)
+	if err != nil {
+		return nil, err
+	}
+
 	switch contentType {
 	case "application/yaml":
 		if err = yaml.Unmarshal(input, &obj); err != nil {
 			return nil, err
 		}
 
-		doCleanup(obj)
+		c.doCleanup(obj)
+
+		styler, err := newFlowStyler(opts)
+		if err != nil {
+			return nil, err
+		}
+		if styler.enabled() {
+			if err := styler.apply(obj); err != nil {
+				return nil, err
+			}
+		}
 
-		if output, err = yaml.Marshal(obj); err != nil {
+		// output has to be the same on every run, so map iteration order must never
+		// leak into it; yaml.Marshal goes through encoding/json and then yaml.v2,
+		// both of which sort map keys, and the ordered path sorts the rest itself
+		if opts.KeyOrder == Alphabetical {
+			output, err = yaml.Marshal(obj)
+		} else {
+			output, err = marshalOrderedYAML(obj, loadKeyOrderReference(input), opts.KeyOrder)
+		}
+		if err != nil {
 			return nil, err
 		}
+		output = styler.restore(output)
+		if opts.Explain {
+			output = explain(output)
+		}
 		return output, nil
 	case "application/json":
 		if err = json.Unmarshal(input, &obj); err != nil {
 			return nil, err
 		}
 
-		doCleanup(obj)
+		c.doCleanup(obj)
 
-		if pretty {
+		switch {
+		case opts.KeyOrder != Alphabetical:
+			output, err = marshalOrderedJSON(obj, loadKeyOrderReference(input), opts.KeyOrder, opts.Pretty)
+		case opts.Pretty:
 			output, err = json.MarshalIndent(obj, "", "  ")
-		} else {
+		default:
 			output, err = json.Marshal(obj)
 		}
 		if err != nil {
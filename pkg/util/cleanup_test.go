@@ -0,0 +1,69 @@
+package util
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEncodeIsDeterministic(t *testing.T) {
+	labels := map[string]string{}
+	annotations := map[string]string{}
+	data := map[string]string{}
+	env := []corev1.EnvVar{}
+	for i := 0; i < 20; i++ {
+		labels[fmt.Sprintf("label-%d", i)] = fmt.Sprintf("%d", i)
+		annotations[fmt.Sprintf("example.com/annotation-%d", i)] = fmt.Sprintf("value %d", i)
+		data[fmt.Sprintf("file-%d.conf", i)] = fmt.Sprintf("key = %d\n", i)
+		env = append(env, corev1.EnvVar{Name: fmt.Sprintf("VAR_%d", i), Value: fmt.Sprintf("%d", i)})
+	}
+
+	deployment := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: labels, Annotations: annotations},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels, Annotations: annotations},
+				Spec: corev1.PodSpec{
+					Containers:   []corev1.Container{{Name: "web", Image: "nginx", Env: env}},
+					NodeSelector: labels,
+				},
+			},
+		},
+	}
+	configMap := &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: labels, Annotations: annotations},
+		Data:       data,
+	}
+	list := &metav1.List{Items: []runtime.RawExtension{{Object: deployment}, {Object: configMap}}}
+
+	for name, opts := range map[string]EncodeOptions{
+		"alphabetical":  {},
+		"canonical":     {KeyOrder: Canonical},
+		"as is":         {KeyOrder: AsIs},
+		"pretty":        {Pretty: true, FlowStyleForScalarSequences: true},
+		"with defaults": {DefaultNamespace: "default", Explain: true},
+	} {
+		for _, contentType := range []string{"application/yaml", "application/json"} {
+			first, err := EncodeListWithOptions(list, contentType, opts)
+			if !assert.NoError(t, err, name) {
+				continue
+			}
+			for i := 0; i < 50; i++ {
+				output, err := EncodeListWithOptions(list, contentType, opts)
+				if !assert.NoError(t, err, name) || !assert.Equal(t, string(first), string(output), "%s %s output differs on run #%d", name, contentType, i) {
+					break
+				}
+			}
+		}
+	}
+}
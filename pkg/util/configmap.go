@@ -0,0 +1,44 @@
+package util
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ListToConfigMap renders each item of the list and stores it in a single ConfigMap,
+// using the filename that DumpListToFiles would give the item as the key
+func ListToConfigMap(list *metav1.List, name, namespace, contentType string) (*corev1.ConfigMap, error) {
+	configMap := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Data: make(map[string]string),
+	}
+
+	for _, item := range list.Items {
+		filename, err := FileNameFor(item.Object, contentType)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := configMap.Data[filename]; ok {
+			return nil, fmt.Errorf("kubegen/util: error adding %q to ConfigMap %q – duplicate key", filename, name)
+		}
+
+		data, err := Encode(item.Object, contentType, true)
+		if err != nil {
+			return nil, err
+		}
+
+		configMap.Data[filename] = string(data)
+	}
+
+	return configMap, nil
+}
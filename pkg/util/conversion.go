@@ -0,0 +1,41 @@
+package util
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kubernetes/pkg/api/legacyscheme"
+
+	// register internal types and conversion functions
+	_ "k8s.io/kubernetes/pkg/apis/apps/install"
+	_ "k8s.io/kubernetes/pkg/apis/batch/install"
+	_ "k8s.io/kubernetes/pkg/apis/core/install"
+	_ "k8s.io/kubernetes/pkg/apis/extensions/install"
+)
+
+// ConvertObject converts object to a different version using conversion functions of
+// Kubernetes, e.g. an extensions/v1beta1 Deployment to apps/v1; unlike copying via JSON,
+// it handles fields that were renamed or restructured between versions
+func ConvertObject(object runtime.Object, target schema.GroupVersionKind) (runtime.Object, error) {
+	source := object.GetObjectKind().GroupVersionKind()
+	if source.Kind != "" && source.Kind != target.Kind {
+		return nil, fmt.Errorf("kubegen/util: error converting %s – cannot convert to a different kind %s", describeObject(object), target.Kind)
+	}
+
+	// versioned objects can only be converted to and from internal objects, which may
+	// belong to a different group (e.g. Deployments of apps/v1 are converted to those
+	// of extensions), so object is converted to the internal version of its own group
+	// first, and then to the target version
+	internal, err := legacyscheme.Scheme.ConvertToVersion(object, schema.GroupVersion{Group: source.Group, Version: runtime.APIVersionInternal})
+	if err != nil {
+		return nil, fmt.Errorf("kubegen/util: error converting %s to %s – %v", describeObject(object), target.GroupVersion(), err)
+	}
+	converted, err := legacyscheme.Scheme.ConvertToVersion(internal, target.GroupVersion())
+	if err != nil {
+		return nil, fmt.Errorf("kubegen/util: error converting %s to %s – %v", describeObject(object), target.GroupVersion(), err)
+	}
+	converted.GetObjectKind().SetGroupVersionKind(target)
+
+	return converted, nil
+}
@@ -0,0 +1,73 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	appsv1 "k8s.io/api/apps/v1"
+	appsv1beta1 "k8s.io/api/apps/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newConversionTestDeployment() *extensionsv1beta1.Deployment {
+	replicas := int32(3)
+	revisionHistoryLimit := int32(5)
+	return &extensionsv1beta1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "extensions/v1beta1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "prod", Labels: map[string]string{"app": "web"}},
+		Spec: extensionsv1beta1.DeploymentSpec{
+			Replicas:             &replicas,
+			RevisionHistoryLimit: &revisionHistoryLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web", "tier": "frontend"}},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "web", Image: "nginx"}},
+				},
+			},
+			RollbackTo: &extensionsv1beta1.RollbackConfig{Revision: 2},
+		},
+	}
+}
+
+func TestConvertObject(t *testing.T) {
+	assert := assert.New(t)
+
+	deployment := newConversionTestDeployment()
+	deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+
+	target := schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+	converted, err := ConvertObject(deployment, target)
+	if !assert.NoError(err) {
+		return
+	}
+	result, ok := converted.(*appsv1.Deployment)
+	if !assert.True(ok, "converted to %T", converted) {
+		return
+	}
+	assert.Equal(target, result.GroupVersionKind())
+	assert.Equal("web", result.Name)
+	assert.Equal("prod", result.Namespace)
+	assert.Equal(int32(3), *result.Spec.Replicas)
+	assert.Equal(int32(5), *result.Spec.RevisionHistoryLimit)
+	assert.Equal(map[string]string{"app": "web"}, result.Spec.Selector.MatchLabels)
+	assert.Equal(deployment.Spec.Template.Labels, result.Spec.Template.Labels)
+	assert.Equal(deployment.Spec.Template.Spec.Containers, result.Spec.Template.Spec.Containers)
+
+	// and back, within the same group as well
+	converted, err = ConvertObject(result, schema.GroupVersionKind{Group: "apps", Version: "v1beta1", Kind: "Deployment"})
+	if assert.NoError(err) {
+		assert.IsType(&appsv1beta1.Deployment{}, converted)
+	}
+	converted, err = ConvertObject(result, schema.GroupVersionKind{Group: "extensions", Version: "v1beta1", Kind: "Deployment"})
+	if assert.NoError(err) {
+		assert.Equal(deployment.Spec.Template.Spec.Containers, converted.(*extensionsv1beta1.Deployment).Spec.Template.Spec.Containers)
+	}
+
+	_, err = ConvertObject(deployment, schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "StatefulSet"})
+	assert.Error(err)
+}
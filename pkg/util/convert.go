@@ -0,0 +1,88 @@
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/ghodss/yaml"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// Convert re-encodes a manifest from one format to another without decoding it
+// into Go types, the cleanup rules get applied just like they do in Encode;
+// every document of a YAML stream (or of concatenated JSON objects) gets
+// converted, YAML output separates them with "---", JSON output is concatenated
+func Convert(data []byte, fromContentType, toContentType string) ([]byte, error) {
+	// next returns JSON of the next document, or io.EOF when there are no more
+	var next func() ([]byte, error)
+
+	switch fromContentType {
+	case "application/yaml":
+		reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+		next = func() ([]byte, error) {
+			doc, err := reader.Read()
+			if err != nil {
+				if err == io.EOF {
+					return nil, err
+				}
+				return nil, fmt.Errorf("kubegen/util: error converting from YAML – %v", err)
+			}
+			if doc, err = yaml.YAMLToJSON(doc); err != nil {
+				return nil, fmt.Errorf("kubegen/util: error converting from YAML – %v", err)
+			}
+			return doc, nil
+		}
+	case "application/json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		next = func() ([]byte, error) {
+			doc := json.RawMessage{}
+			if err := decoder.Decode(&doc); err != nil {
+				if err == io.EOF {
+					return nil, err
+				}
+				return nil, fmt.Errorf("kubegen/util: error converting from JSON – invalid JSON data – %v", err)
+			}
+			data, err := EnsureJSON(doc)
+			if err != nil {
+				return nil, fmt.Errorf("kubegen/util: error converting from JSON – %v", err)
+			}
+			return data, nil
+		}
+	default:
+		return nil, fmt.Errorf("kubegen/util: error converting from %q – unsupported content type", fromContentType)
+	}
+
+	if _, err := fileExtensionFor(toContentType); err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	for n := 0; ; n++ {
+		doc, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%v (document #%d)", err, n)
+		}
+
+		doc = bytes.TrimSpace(doc)
+		if len(doc) == 0 || bytes.Equal(doc, []byte("null")) {
+			continue
+		}
+
+		output, err := encodeJSON(doc, toContentType, EncodeOptions{Pretty: true})
+		if err != nil {
+			return nil, fmt.Errorf("kubegen/util: error converting document #%d to %q – %v", n, toContentType, err)
+		}
+		if buf.Len() > 0 && toContentType == "application/yaml" {
+			buf.WriteString("---\n")
+		}
+		buf.Write(output)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,51 @@
+package util
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvert(t *testing.T) {
+	assert := assert.New(t)
+
+	const (
+		configMap = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n"
+		secret    = "apiVersion: v1\nkind: Secret\nmetadata:\n  name: b\n"
+	)
+
+	output, err := Convert([]byte(configMap), "application/yaml", "application/json")
+	if assert.NoError(err) {
+		assert.True(bytes.HasPrefix(output, []byte("{")))
+		assert.True(bytes.HasSuffix(output, []byte("}\n")), "output must end with exactly one newline")
+		assert.Contains(string(output), `"name": "a"`)
+	}
+
+	stream := "---\n" + configMap + "---\n" + secret + "---\n"
+	output, err = Convert([]byte(stream), "application/yaml", "application/yaml")
+	if assert.NoError(err) {
+		assert.Equal(configMap+"---\n"+secret, string(output))
+	}
+
+	output, err = Convert([]byte(stream), "application/yaml", "application/json")
+	if assert.NoError(err) {
+		list, err := DecodeAll(output)
+		if assert.NoError(err) && assert.Len(list.Items, 2) {
+			assert.Equal("ConfigMap", list.Items[0].Object.GetObjectKind().GroupVersionKind().Kind)
+			assert.Equal("Secret", list.Items[1].Object.GetObjectKind().GroupVersionKind().Kind)
+		}
+
+		output, err = Convert(output, "application/json", "application/yaml")
+		if assert.NoError(err) {
+			assert.Equal(configMap+"---\n"+secret, string(output))
+		}
+	}
+
+	_, err = Convert([]byte(configMap), "application/json", "application/yaml")
+	assert.Error(err)
+	_, err = Convert([]byte(configMap+"---\nkind: [Secret\n"), "application/yaml", "application/json")
+	assert.Error(err)
+	_, err = Convert([]byte(configMap), "application/yaml", "text/plain")
+	assert.Error(err)
+}
@@ -0,0 +1,84 @@
+package util
+
+import (
+	"fmt"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// typesScheme is used by Decode, DecodeAll and conversions, it's separate from the scheme of
+// client-go, so that registering custom types here doesn't affect other users of client-go
+var (
+	typesScheme = newTypesScheme()
+	typesCodecs = serializer.NewCodecFactory(typesScheme)
+)
+
+func newTypesScheme() *runtime.Scheme {
+	s := runtime.NewScheme()
+	metav1.AddToGroupVersion(s, schema.GroupVersion{Version: "v1"})
+	scheme.AddToScheme(s)
+	return s
+}
+
+var (
+	customKindsLock sync.RWMutex
+	customKinds     = make(map[schema.GroupVersionKind]Scope)
+)
+
+func customKindScope(gvk schema.GroupVersionKind) (Scope, bool) {
+	customKindsLock.RLock()
+	defer customKindsLock.RUnlock()
+	scope, ok := customKinds[gvk]
+	return scope, ok
+}
+
+// RegisterTypes adds types of custom resources of the given group version to the scheme used
+// by Decode and DecodeAll, it's meant to be called with the same arguments as AddKnownTypes
+// in the register.go of an API group, along with the scope of the resources; once registered,
+// objects of these types can be decoded, converted and encoded, DumpListToFiles uses lower-case
+// kind as the filename suffix, and EncodeOptions.DefaultNamespace applies if they are Namespaced;
+// the scheme is not safe for concurrent modification, so RegisterTypes must only be called during
+// initialisation (e.g. from an init function), before anything is decoded or encoded
+func RegisterTypes(gv schema.GroupVersion, scope Scope, types ...runtime.Object) error {
+	typesScheme.AddKnownTypes(gv, types...)
+	metav1.AddToGroupVersion(typesScheme, gv)
+
+	customKindsLock.Lock()
+	defer customKindsLock.Unlock()
+	for _, obj := range types {
+		gvks, _, err := typesScheme.ObjectKinds(obj)
+		if err != nil {
+			return fmt.Errorf("kubegen/util: error registering custom type %T – %v", obj, err)
+		}
+		for _, gvk := range gvks {
+			if gvk.GroupVersion() == gv {
+				customKinds[gvk] = scope
+			}
+		}
+	}
+	return nil
+}
+
+// DecodeWithScheme is like Decode, but uses the given scheme instead of the built-in one
+func DecodeWithScheme(data []byte, s *runtime.Scheme) (runtime.Object, error) {
+	data, _, err := maybeGunzip(data, "")
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := runtime.Decode(serializer.NewCodecFactory(s).UniversalDeserializer(), data)
+	if runtime.IsNotRegisteredError(err) {
+		return decodeUnstructured(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("kubegen/util: error decoding object – %v", err)
+	}
+
+	return obj, nil
+}
@@ -0,0 +1,58 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type customTypesTestWidget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Size              int `json:"size"`
+}
+
+func (w *customTypesTestWidget) DeepCopyObject() runtime.Object {
+	c := *w
+	w.ObjectMeta.DeepCopyInto(&c.ObjectMeta)
+	return &c
+}
+
+func TestRegisterTypes(t *testing.T) {
+	assert := assert.New(t)
+
+	gv := schema.GroupVersion{Group: "customtypes.kubegen.test", Version: "v1"}
+	gvk := gv.WithKind("customTypesTestWidget")
+
+	data := []byte("apiVersion: customtypes.kubegen.test/v1\nkind: customTypesTestWidget\nmetadata:\n  name: small\nsize: 2\n")
+
+	object, err := Decode(data)
+	if assert.NoError(err) {
+		// objects of kinds that aren't registered are decoded as unstructured
+		assert.IsType(&unstructured.Unstructured{}, object)
+	}
+
+	if !assert.NoError(RegisterTypes(gv, Namespaced, &customTypesTestWidget{})) {
+		return
+	}
+
+	object, err = Decode(data)
+	if assert.NoError(err) {
+		widget, ok := object.(*customTypesTestWidget)
+		if assert.True(ok, "decoded as %T", object) {
+			assert.Equal("small", widget.Name)
+			assert.Equal(2, widget.Size)
+		}
+	}
+
+	// the scheme of client-go is left alone
+	assert.False(scheme.Scheme.Recognizes(gvk))
+	assert.True(typesScheme.Recognizes(gvk))
+}
@@ -0,0 +1,62 @@
+package util
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// digestsOf returns digests of normalised encodings of items in the list, keyed
+// by identity of each object, along with identities in the order of the list
+func digestsOf(list *metav1.List, contentType string) (map[string]string, []string, error) {
+	digests := make(map[string]string, len(list.Items))
+	ids := []string{}
+	for _, item := range list.Items {
+		if item.Object == nil {
+			continue
+		}
+		id := identityOf(item.Object)
+		if _, ok := digests[id]; ok {
+			return nil, nil, fmt.Errorf("kubegen/util: error comparing lists – duplicate object %s", id)
+		}
+		data, err := EncodeWithOptions(item.Object, contentType, EncodeOptions{StripStatus: true})
+		if err != nil {
+			return nil, nil, err
+		}
+		digests[id] = sha256Of(data)
+		ids = append(ids, id)
+	}
+	return digests, ids, nil
+}
+
+// DiffLists matches objects in the two lists by kind, namespace and name, and
+// returns identities (e.g. "apps/v1/Deployment/default/web") of objects that only
+// appear in new, objects that only appear in old, and objects that would be encoded
+// differently; status is ignored, and names are in the order objects appear in the lists
+func DiffLists(old, new *metav1.List, contentType string) (added, removed, changed []string, err error) {
+	oldDigests, oldIDs, err := digestsOf(old, contentType)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	newDigests, newIDs, err := digestsOf(new, contentType)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	added, removed, changed = []string{}, []string{}, []string{}
+	for _, id := range newIDs {
+		digest, ok := oldDigests[id]
+		switch {
+		case !ok:
+			added = append(added, id)
+		case digest != newDigests[id]:
+			changed = append(changed, id)
+		}
+	}
+	for _, id := range oldIDs {
+		if _, ok := newDigests[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+	return added, removed, changed, nil
+}
@@ -0,0 +1,43 @@
+package util
+
+import (
+	"fmt"
+	"regexp"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var imageDigest = regexp.MustCompile(`@sha256:[0-9a-f]{64}$`)
+
+// isPinnedByDigest checks whether image refers to a sha256 digest, e.g.
+// "nginx@sha256:..." or "nginx:1.13@sha256:...", rather than only a tag
+func isPinnedByDigest(image string) bool {
+	return imageDigest.MatchString(image)
+}
+
+func imageDigestProblems(podSpec *corev1.PodSpec) []string {
+	problems := []string{}
+	rangeOverContainers(podSpec, func(container *corev1.Container) {
+		if !isPinnedByDigest(container.Image) {
+			problems = append(problems, fmt.Sprintf("container %q has image %q, which is not pinned by digest", container.Name, container.Image))
+		}
+	})
+	return problems
+}
+
+// CheckImageDigests warns about every container with an image
+// that is referred to by tag, instead of a sha256 digest
+func CheckImageDigests(list *metav1.List) []Warning {
+	warnings := []Warning{}
+
+	rangeOverPods(list, func(workload runtime.Object, _ *metav1.ObjectMeta, podSpec *corev1.PodSpec) {
+		for _, problem := range imageDigestProblems(podSpec) {
+			warnings = append(warnings, Warning{Object: describeObject(workload), Message: problem})
+		}
+	})
+
+	return warnings
+}
@@ -0,0 +1,42 @@
+package util
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Encoder encodes objects with a fixed set of options, it's safe for concurrent use
+type Encoder struct {
+	opts EncodeOptions
+}
+
+// NewEncoder returns an Encoder that uses a copy of opts, so that
+// modifying opts afterwards has no effect on the encoder
+func NewEncoder(opts EncodeOptions) *Encoder {
+	opts.StripKeys = append([]string{}, opts.StripKeys...)
+	opts.FlowStyle = append([]string{}, opts.FlowStyle...)
+	opts.Cleanup.Only = append([]string{}, opts.Cleanup.Only...)
+	if opts.Cleanup.KeepEmpty != nil {
+		keepEmpty := make(map[string][]string, len(opts.Cleanup.KeepEmpty))
+		for kind, paths := range opts.Cleanup.KeepEmpty {
+			keepEmpty[kind] = append([]string{}, paths...)
+		}
+		opts.Cleanup.KeepEmpty = keepEmpty
+	}
+	return &Encoder{opts: opts}
+}
+
+func (e *Encoder) Encode(object runtime.Object, contentType string) ([]byte, error) {
+	return EncodeWithOptions(object, contentType, e.opts)
+}
+
+func (e *Encoder) EncodeList(list *metav1.List, contentType string) ([]byte, error) {
+	return EncodeListWithOptions(list, contentType, e.opts)
+}
+
+// DumpListToFiles is like DumpListToFilesWithOptions, opts.Encode is replaced with the encoder's options
+func (e *Encoder) DumpListToFiles(list *metav1.List, contentType string, opts DumpOptions) ([]string, error) {
+	opts.Encode = e.opts
+	return DumpListToFilesWithOptions(list, contentType, opts)
+}
@@ -0,0 +1,222 @@
+package util
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// ExplainHeader is the first line of YAML output when EncodeOptions.Explain is enabled
+const ExplainHeader = "# educational output: comments below explain common fields, they can be safely removed"
+
+// explanations of common fields, paths are relative to each object
+var explanations = []struct {
+	path string
+	text string
+}{
+	{"metadata.namespace", "namespace the object belongs to"},
+	{"metadata.labels", "labels identify the object and are used by selectors"},
+	{"metadata.annotations", "annotations hold arbitrary non-identifying information"},
+	{"spec.replicas", "number of pod replicas"},
+	{"spec.selector", "labels used to find the pods this object manages"},
+	{"spec.strategy", "how existing pods are replaced with new ones on update"},
+	{"spec.template", "template of the pods this object creates"},
+	{"spec.template.spec.containers", "containers that run in each pod"},
+	{"spec.template.spec.containers[*].image", "container image to run"},
+	{"spec.template.spec.containers[*].ports", "ports the container listens on"},
+	{"spec.template.spec.containers[*].env", "environment variables set in the container"},
+	{"spec.template.spec.containers[*].resources", "CPU and memory the container requests and is limited to"},
+	{"spec.template.spec.volumes", "volumes that containers of the pod can mount"},
+	{"spec.type", "how the Service is exposed, e.g. ClusterIP, NodePort or LoadBalancer"},
+	{"spec.ports", "ports the Service exposes and which container ports they forward to"},
+	{"data", "configuration data as key/value pairs"},
+}
+
+var explanationPatterns = func() []fieldPathPattern {
+	patterns := make([]fieldPathPattern, len(explanations))
+	for n, explanation := range explanations {
+		pattern, err := parseFieldPathPattern(explanation.path)
+		if err != nil {
+			panic(err)
+		}
+		patterns[n] = pattern
+	}
+	return patterns
+}()
+
+func explanationOf(path fieldPath) (string, bool) {
+	// items of a list are explained the same way as a single object
+	if len(path) > 2 && path[0] == "items" {
+		if _, ok := path[1].(int); ok {
+			path = path[2:]
+		}
+	}
+	for n, pattern := range explanationPatterns {
+		if pattern.matches(path) {
+			return explanations[n].text, true
+		}
+	}
+	return "", false
+}
+
+// yamlLine is a line of block-style YAML broken down into its parts
+type yamlLine struct {
+	indent int
+	// items is the number of "- " prefixes, i.e. the number of sequences the line
+	// starts an item of, e.g. two for an item of a sequence nested in a sequence
+	items  int
+	key    string
+	hasKey bool
+	value  string
+}
+
+// parseYAMLLine breaks down a line in the form that yaml.v2 produces, keys can be
+// plain, single-quoted or double-quoted; lines that hold neither a key nor an item
+// (e.g. continuation of a multi-line scalar) come out with hasKey and items unset
+func parseYAMLLine(content string) yamlLine {
+	rest := strings.TrimLeft(content, " ")
+	line := yamlLine{indent: len(content) - len(rest)}
+
+	for strings.HasPrefix(rest, "- ") || rest == "-" {
+		line.items++
+		rest = strings.TrimPrefix(rest[1:], " ")
+	}
+
+	end := -1
+	switch {
+	case rest == "":
+		return line
+	case rest[0] == '"':
+		for i := 1; i < len(rest); i++ {
+			if rest[i] == '\\' {
+				i++
+			} else if rest[i] == '"' {
+				end = i + 1
+				break
+			}
+		}
+	case rest[0] == '\'':
+		for i := 1; i < len(rest); i++ {
+			if rest[i] == '\'' {
+				if i+1 < len(rest) && rest[i+1] == '\'' {
+					i++
+					continue
+				}
+				end = i + 1
+				break
+			}
+		}
+	case strings.ContainsRune("#?[{|>&*!%@`", rune(rest[0])):
+		return line
+	default:
+		if end = strings.Index(rest, ": "); end < 0 && strings.HasSuffix(rest, ":") {
+			end = len(rest) - 1
+		}
+	}
+	if end <= 0 || !(strings.HasPrefix(rest[end:], ": ") || rest[end:] == ":") {
+		line.value = rest
+		return line
+	}
+
+	line.key, line.hasKey = unquoteYAMLKey(rest[:end]), true
+	line.value = strings.TrimPrefix(rest[end+1:], " ")
+	return line
+}
+
+func unquoteYAMLKey(key string) string {
+	switch key[0] {
+	case '"':
+		if unquoted, err := strconv.Unquote(key); err == nil {
+			return unquoted
+		}
+		return strings.Trim(key, `"`)
+	case '\'':
+		return strings.Replace(key[1:len(key)-1], "''", "'", -1)
+	}
+	return key
+}
+
+type yamlPathElem struct {
+	indent int
+	elem   interface{}
+}
+
+// explain inserts a comment above each of the common fields in YAML output; it relies on
+// the layout that yaml.v2 produces, i.e. 2-space indentation and sequences that are not
+// indented within mappings, and skips over the contents of multi-line scalars
+func explain(data []byte) []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteString(ExplainHeader + "\n")
+
+	stack := []yamlPathElem{}
+	pop := func(indent int) {
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+	}
+	path := func() fieldPath {
+		p := fieldPath{}
+		for _, e := range stack {
+			p = append(p, e.elem)
+		}
+		return p
+	}
+
+	// lines indented deeper than scalarIndent belong to a multi-line scalar
+	scalarIndent := -1
+
+	lines := strings.SplitAfter(string(data), "\n")
+	for _, line := range lines {
+		content := strings.TrimRight(line, "\r\n")
+		l := parseYAMLLine(content)
+		if content == "" || strings.HasPrefix(strings.TrimSpace(content), "#") || content == "---" ||
+			(scalarIndent >= 0 && l.indent > scalarIndent) {
+			buf.WriteString(line)
+			continue
+		}
+
+		// each "- " starts an item of a sequence, which is given the indentation of the
+		// dash plus one, so that the next item pops it and anything nested in it
+		keyIndent := l.indent
+		for n := 0; n < l.items; n++ {
+			dash := l.indent + 2*n
+			pop(dash + 2)
+			if top := len(stack) - 1; top >= 0 && stack[top].indent == dash+1 {
+				stack[top].elem = stack[top].elem.(int) + 1
+			} else {
+				stack = append(stack, yamlPathElem{indent: dash + 1, elem: 0})
+			}
+			keyIndent = dash + 2
+		}
+
+		if !l.hasKey {
+			// a scalar item of a sequence, or something this doesn't understand,
+			// in which case anything indented deeper is left as it is
+			scalarIndent = keyIndent - 2
+			if l.items == 0 {
+				scalarIndent = keyIndent
+			}
+			buf.WriteString(line)
+			continue
+		}
+
+		pop(keyIndent)
+		stack = append(stack, yamlPathElem{indent: keyIndent, elem: l.key})
+
+		if text, ok := explanationOf(path()); ok {
+			lineEnding := line[len(content):]
+			if lineEnding == "" {
+				lineEnding = "\n"
+			}
+			buf.WriteString(strings.Repeat(" ", l.indent) + "# " + text + lineEnding)
+		}
+		buf.WriteString(line)
+
+		if l.value == "" {
+			scalarIndent = -1
+		} else {
+			scalarIndent = keyIndent
+		}
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,219 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newExplainTestObjects() (*appsv1.Deployment, *corev1.ConfigMap) {
+	replicas := int32(2)
+	deployment := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: map[string]string{"app": "web"}},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "web", Image: "nginx", Ports: []corev1.ContainerPort{{ContainerPort: 80}}},
+						{Name: "sidecar", Image: "envoy", Args: []string{"--port", "9901"}},
+					},
+				},
+			},
+		},
+	}
+	configMap := &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "app"},
+		// contents of a multi-line scalar that look like fields must not be explained
+		Data: map[string]string{"app.yaml": "spec:\n  replicas: 3\ndata: x\n", "mode": "production"},
+	}
+	return deployment, configMap
+}
+
+func TestExplainDeployment(t *testing.T) {
+	deployment, _ := newExplainTestObjects()
+
+	expected := ExplainHeader + `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  # labels identify the object and are used by selectors
+  labels:
+    app: web
+  name: web
+spec:
+  # number of pod replicas
+  replicas: 2
+  # labels used to find the pods this object manages
+  selector:
+    matchLabels:
+      app: web
+  # template of the pods this object creates
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      # containers that run in each pod
+      containers:
+      # container image to run
+      - image: nginx
+        name: web
+        # ports the container listens on
+        ports:
+        - containerPort: 80
+      - args:
+        - --port
+        - "9901"
+        # container image to run
+        image: envoy
+        name: sidecar
+`
+
+	data, err := EncodeWithOptions(deployment, "application/yaml", EncodeOptions{Explain: true})
+	if assert.NoError(t, err) {
+		assert.Equal(t, expected, string(data))
+	}
+}
+
+func TestExplainList(t *testing.T) {
+	deployment, configMap := newExplainTestObjects()
+	list := &metav1.List{Items: []runtime.RawExtension{{Object: configMap}, {Object: deployment}}}
+
+	expected := ExplainHeader + `
+items:
+- apiVersion: v1
+  # configuration data as key/value pairs
+  data:
+    app.yaml: |
+      spec:
+        replicas: 3
+      data: x
+    mode: production
+  kind: ConfigMap
+  metadata:
+    name: app
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    # labels identify the object and are used by selectors
+    labels:
+      app: web
+    name: web
+  spec:
+    # number of pod replicas
+    replicas: 2
+    # labels used to find the pods this object manages
+    selector:
+      matchLabels:
+        app: web
+    # template of the pods this object creates
+    template:
+      metadata:
+        labels:
+          app: web
+      spec:
+        # containers that run in each pod
+        containers:
+        # container image to run
+        - image: nginx
+          name: web
+          # ports the container listens on
+          ports:
+          - containerPort: 80
+        - args:
+          - --port
+          - "9901"
+          # container image to run
+          image: envoy
+          name: sidecar
+`
+
+	data, err := EncodeListWithOptions(list, "application/yaml", EncodeOptions{Explain: true})
+	if assert.NoError(t, err) {
+		assert.Equal(t, expected, string(data))
+	}
+}
+
+func TestExplainQuotedKeysAndNestedSequences(t *testing.T) {
+	input := `metadata:
+  annotations:
+    '#note': |
+      labels:
+        x: y
+    '''quoted''': 'a: b'
+    "on": "yes"
+    a:b: c
+  labels:
+    app: web
+spec:
+  matrix:
+  - - x
+    - - "y"
+  - - replicas: 1
+      data: 2
+  replicas: 3
+data:
+  key: value
+`
+
+	expected := ExplainHeader + `
+metadata:
+  # annotations hold arbitrary non-identifying information
+  annotations:
+    '#note': |
+      labels:
+        x: y
+    '''quoted''': 'a: b'
+    "on": "yes"
+    a:b: c
+  # labels identify the object and are used by selectors
+  labels:
+    app: web
+spec:
+  matrix:
+  - - x
+    - - "y"
+  - - replicas: 1
+      data: 2
+  # number of pod replicas
+  replicas: 3
+# configuration data as key/value pairs
+data:
+  key: value
+`
+
+	assert.Equal(t, expected, string(explain([]byte(input))))
+}
+
+func TestParseYAMLLine(t *testing.T) {
+	for content, expected := range map[string]yamlLine{
+		"kind: Deployment":     {key: "kind", hasKey: true, value: "Deployment"},
+		"  labels:":            {indent: 2, key: "labels", hasKey: true},
+		`  "on": "yes"`:        {indent: 2, key: "on", hasKey: true, value: `"yes"`},
+		`  "a\"b": c`:          {indent: 2, key: `a"b`, hasKey: true, value: "c"},
+		"  '#x': 1":            {indent: 2, key: "#x", hasKey: true, value: "1"},
+		"  '''x''': 1":         {indent: 2, key: "'x'", hasKey: true, value: "1"},
+		"  'a: b':":            {indent: 2, key: "a: b", hasKey: true},
+		"a:b: c":               {key: "a:b", hasKey: true, value: "c"},
+		"- image: nginx":       {items: 1, key: "image", hasKey: true, value: "nginx"},
+		"  - - x":              {indent: 2, items: 2, value: "x"},
+		"- - - name: x":        {items: 3, key: "name", hasKey: true, value: "x"},
+		"- http://example.com": {items: 1, value: "http://example.com"},
+		"  continued line":     {indent: 2, value: "continued line"},
+		"? |-":                 {},
+		": 13":                 {value: ": 13"},
+	} {
+		assert.Equal(t, expected, parseYAMLLine(content), content)
+	}
+}
@@ -0,0 +1,83 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	corev1 "k8s.io/api/core/v1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ServiceForDeployment returns a Service with selector that matches pod labels of the
+// Deployment and a port for each of the container ports, ports are named after container
+// ports, or after the protocol and number when there is more than one port without a name
+func ServiceForDeployment(dep *extensionsv1beta1.Deployment, serviceType corev1.ServiceType) (*corev1.Service, error) {
+	labels := dep.Spec.Template.Labels
+	if len(labels) == 0 {
+		return nil, fmt.Errorf("kubegen/util: unable to expose deployment %q – pod template has no labels", dep.Name)
+	}
+
+	ports := []corev1.ServicePort{}
+	seen := map[string]bool{}
+	// init containers exit before the pod is ready, so their ports are not exposed
+	for _, container := range dep.Spec.Template.Spec.Containers {
+		for _, port := range container.Ports {
+			protocol := port.Protocol
+			if protocol == "" {
+				protocol = corev1.ProtocolTCP
+			}
+			key := fmt.Sprintf("%d/%s", port.ContainerPort, protocol)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			servicePort := corev1.ServicePort{
+				Name:       port.Name,
+				Protocol:   protocol,
+				Port:       port.ContainerPort,
+				TargetPort: intstr.FromInt(int(port.ContainerPort)),
+			}
+			if port.Name != "" {
+				servicePort.TargetPort = intstr.FromString(port.Name)
+			}
+			ports = append(ports, servicePort)
+		}
+	}
+	if len(ports) == 0 {
+		return nil, fmt.Errorf("kubegen/util: unable to expose deployment %q – no container ports", dep.Name)
+	}
+	if len(ports) > 1 {
+		for i := range ports {
+			if ports[i].Name == "" {
+				ports[i].Name = fmt.Sprintf("%s-%d", strings.ToLower(string(ports[i].Protocol)), ports[i].Port)
+			}
+		}
+	}
+
+	selector := make(map[string]string, len(labels))
+	for k, v := range labels {
+		selector[k] = v
+	}
+
+	service := &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      dep.Name,
+			Namespace: dep.Namespace,
+			Labels:    dep.Labels,
+		},
+		Spec: corev1.ServiceSpec{
+			Type:     serviceType,
+			Selector: selector,
+			Ports:    ports,
+		},
+	}
+	return service, nil
+}
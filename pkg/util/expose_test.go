@@ -0,0 +1,84 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	corev1 "k8s.io/api/core/v1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestServiceForDeployment(t *testing.T) {
+	assert := assert.New(t)
+
+	podLabels := map[string]string{"app": "web", "tier": "frontend"}
+	dep := &extensionsv1beta1.Deployment{
+		TypeMeta: metav1.TypeMeta{Kind: "Deployment", APIVersion: "extensions/v1beta1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web",
+			Namespace: "prod",
+			Labels:    map[string]string{"app": "web"},
+		},
+		Spec: extensionsv1beta1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: podLabels},
+				Spec: corev1.PodSpec{
+					InitContainers: []corev1.Container{{
+						Name:  "migrate",
+						Image: "migrate",
+						Ports: []corev1.ContainerPort{{ContainerPort: 9000}},
+					}},
+					Containers: []corev1.Container{
+						{
+							Name:  "web",
+							Image: "nginx",
+							Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 80}, {ContainerPort: 80}},
+						},
+						{
+							Name:  "metrics",
+							Image: "exporter",
+							Ports: []corev1.ContainerPort{{ContainerPort: 9102}, {ContainerPort: 53, Protocol: corev1.ProtocolUDP}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	service, err := ServiceForDeployment(dep, corev1.ServiceTypeNodePort)
+	if !assert.NoError(err) {
+		return
+	}
+
+	assert.Equal("web", service.Name)
+	assert.Equal("prod", service.Namespace)
+	assert.Equal(corev1.ServiceTypeNodePort, service.Spec.Type)
+
+	selector := labels.SelectorFromSet(labels.Set(service.Spec.Selector))
+	assert.True(selector.Matches(labels.Set(dep.Spec.Template.Labels)))
+	assert.Equal(podLabels, service.Spec.Selector)
+
+	list := &metav1.List{Items: []runtime.RawExtension{{Object: dep}, {Object: service}}}
+	assert.Empty(CheckServiceSelectors(list))
+	assert.Empty(CheckServiceTargetPorts(list))
+
+	assert.Equal([]corev1.ServicePort{
+		{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80, TargetPort: intstr.FromString("http")},
+		{Name: "tcp-9102", Protocol: corev1.ProtocolTCP, Port: 9102, TargetPort: intstr.FromInt(9102)},
+		{Name: "udp-53", Protocol: corev1.ProtocolUDP, Port: 53, TargetPort: intstr.FromInt(53)},
+	}, service.Spec.Ports)
+
+	dep.Spec.Template.Spec.Containers = nil
+	_, err = ServiceForDeployment(dep, corev1.ServiceTypeClusterIP)
+	assert.Error(err, "ports of init containers must not be exposed")
+
+	dep.Spec.Template.Labels = nil
+	_, err = ServiceForDeployment(dep, corev1.ServiceTypeClusterIP)
+	assert.Error(err)
+}
@@ -0,0 +1,68 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/ghodss/yaml"
+)
+
+// EncodeField encodes only the value at path within the object, e.g.
+// `spec.template.spec`; the path may contain indices, but not wildcards
+func EncodeField(object runtime.Object, path, contentType string) ([]byte, error) {
+	pattern, err := parseFieldPathPattern(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := encode(object, "application/json", EncodeOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+
+	at := fieldPath{}
+	for _, segment := range pattern {
+		if segment.isIndex {
+			at = at.index(segment.index)
+		} else {
+			at = at.key(segment.key)
+		}
+		notFound := func() error {
+			return fmt.Errorf("kubegen/util: error encoding field %q of %s – %q not found", path, describeObject(object), at.String())
+		}
+		switch {
+		case segment.anyIndex:
+			return nil, fmt.Errorf("kubegen/util: error encoding field %q – wildcards are not supported", path)
+		case segment.isIndex:
+			v, ok := value.([]interface{})
+			if !ok || segment.index >= len(v) {
+				return nil, notFound()
+			}
+			value = v[segment.index]
+		default:
+			v, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, notFound()
+			}
+			if value, ok = v[segment.key]; !ok {
+				return nil, notFound()
+			}
+		}
+	}
+
+	switch contentType {
+	case "application/yaml":
+		return yaml.Marshal(value)
+	case "application/json":
+		return json.MarshalIndent(value, "", "  ")
+	default:
+		_, err := fileExtensionFor(contentType)
+		return nil, err
+	}
+}
@@ -0,0 +1,108 @@
+package util
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func fileExtensionFor(contentType string) (string, error) {
+	switch contentType {
+	case "application/yaml":
+		return "yaml", nil
+	case "application/json":
+		return "json", nil
+	default:
+		return "", fmt.Errorf("kubegen/util: unknown content type %q", contentType)
+	}
+}
+
+// contentTypeForFileName is the inverse of fileExtensionFor
+func contentTypeForFileName(filename string) (string, error) {
+	switch path.Ext(filename) {
+	case ".yaml", ".yml":
+		return "application/yaml", nil
+	case ".json":
+		return "application/json", nil
+	default:
+		return "", fmt.Errorf("kubegen/util: unable to determine content type of %q", filename)
+	}
+}
+
+// kindSuffixes maps kinds to the suffixes of filenames objects get written to,
+// it's pre-populated with the built-in kinds that have a common abbreviation,
+// RegisterKindSuffix adds more
+var (
+	kindSuffixesLock sync.RWMutex
+	kindSuffixes     = map[string]string{
+		"Service":                 "svc",
+		"Deployment":              "dpl",
+		"ReplicaSet":              "rs",
+		"DaemonSet":               "ds",
+		"StatefulSet":             "ss",
+		"StorageClass":            "sc",
+		"Endpoints":               "ep",
+		"Pod":                     "po",
+		"ReplicationController":   "rc",
+		"ConfigMap":               "cm",
+		"ServiceAccount":          "sa",
+		"Namespace":               "ns",
+		"Ingress":                 "ing",
+		"PersistentVolume":        "pv",
+		"PersistentVolumeClaim":   "pvc",
+		"CronJob":                 "cj",
+		"HorizontalPodAutoscaler": "hpa",
+		"PodDisruptionBudget":     "pdb",
+		"NetworkPolicy":           "netpol",
+		// discovery.k8s.io types are not vendored, such objects can only be unstructured
+		"EndpointSlice": "eps",
+	}
+)
+
+// RegisterKindSuffix sets the suffix FileNameFor uses for objects of the given kind,
+// e.g. "cm" for ConfigMap gives "app-cm.yaml"; it overrides suffixes of built-in kinds
+// and of custom resources registered with RegisterTypes
+func RegisterKindSuffix(kind, suffix string) {
+	kindSuffixesLock.Lock()
+	defer kindSuffixesLock.Unlock()
+	kindSuffixes[kind] = suffix
+}
+
+func kindSuffixOf(kind string) (string, bool) {
+	kindSuffixesLock.RLock()
+	defer kindSuffixesLock.RUnlock()
+	suffix, ok := kindSuffixes[kind]
+	return suffix, ok
+}
+
+// FileNameFor returns the name of the file that DumpListToFiles
+// would write the given object to, e.g. "web-svc.yaml"; objects of
+// kinds without a registered suffix (e.g. Secrets or custom resources)
+// get lower-case kind as the suffix, e.g. "db-postgrescluster.yaml"
+func FileNameFor(object runtime.Object, contentType string) (string, error) {
+	gvk := object.GetObjectKind().GroupVersionKind()
+	if gvk.Kind == "" {
+		return "", fmt.Errorf("kubegen/util: unable to derive filename for an object without kind")
+	}
+
+	suffix, ok := kindSuffixOf(gvk.Kind)
+	if !ok {
+		suffix = strings.ToLower(gvk.Kind)
+	}
+
+	objectMeta, err := meta.Accessor(object)
+	if err != nil {
+		return "", fmt.Errorf("kubegen/util: unable to derive filename for %s – %v", gvk.Kind, err)
+	}
+
+	ext, err := fileExtensionFor(contentType)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s-%s.%s", objectMeta.GetName(), suffix, ext), nil
+}
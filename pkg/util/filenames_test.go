@@ -0,0 +1,42 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	corev1 "k8s.io/api/core/v1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFileNameFor(t *testing.T) {
+	assert := assert.New(t)
+
+	objectMeta := metav1.ObjectMeta{Name: "web"}
+
+	tests := []struct {
+		object   runtime.Object
+		filename string
+	}{
+		{&corev1.Service{TypeMeta: metav1.TypeMeta{Kind: "Service", APIVersion: "v1"}, ObjectMeta: objectMeta}, "web-svc.yaml"},
+		{&corev1.ConfigMap{TypeMeta: metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"}, ObjectMeta: objectMeta}, "web-cm.yaml"},
+		{&corev1.ServiceAccount{TypeMeta: metav1.TypeMeta{Kind: "ServiceAccount", APIVersion: "v1"}, ObjectMeta: objectMeta}, "web-sa.yaml"},
+		{&extensionsv1beta1.Ingress{TypeMeta: metav1.TypeMeta{Kind: "Ingress", APIVersion: "extensions/v1beta1"}, ObjectMeta: objectMeta}, "web-ing.yaml"},
+		{&corev1.Secret{TypeMeta: metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"}, ObjectMeta: objectMeta}, "web-secret.yaml"},
+		{&rbacv1.RoleBinding{TypeMeta: metav1.TypeMeta{Kind: "RoleBinding", APIVersion: "rbac.authorization.k8s.io/v1"}, ObjectMeta: objectMeta}, "web-rolebinding.yaml"},
+	}
+
+	for _, test := range tests {
+		filename, err := FileNameFor(test.object, "application/yaml")
+		if assert.NoError(err, test.filename) {
+			assert.Equal(test.filename, filename)
+		}
+	}
+
+	_, err := FileNameFor(&corev1.ConfigMap{ObjectMeta: objectMeta}, "application/yaml")
+	assert.Error(err, "objects without kind have no filename")
+}
@@ -0,0 +1,109 @@
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// FileSystem is what DumpListToFiles writes files to
+type FileSystem interface {
+	WriteFile(name string, data []byte, perm os.FileMode) error
+	MkdirAll(path string, perm os.FileMode) error
+}
+
+// OSFileSystem writes to the local filesystem, each file is replaced atomically
+type OSFileSystem struct{}
+
+func (OSFileSystem) WriteFile(name string, data []byte, perm os.FileMode) error {
+	return writeFileAtomically(name, data, perm)
+}
+
+func (OSFileSystem) MkdirAll(path string, perm os.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+// MemoryFileSystem keeps files in memory, it's useful for tests
+// and in environments where there is no real filesystem
+type MemoryFileSystem struct {
+	mutex sync.Mutex
+	files map[string][]byte
+}
+
+func NewMemoryFileSystem() *MemoryFileSystem {
+	return &MemoryFileSystem{files: make(map[string][]byte)}
+}
+
+func (fs *MemoryFileSystem) WriteFile(name string, data []byte, _ os.FileMode) error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	fs.files[filepath.Clean(name)] = append([]byte{}, data...)
+	return nil
+}
+
+// MkdirAll does nothing, as directories are implied by file names
+func (fs *MemoryFileSystem) MkdirAll(_ string, _ os.FileMode) error {
+	return nil
+}
+
+// ReadFile returns contents of a file previously written to fs
+func (fs *MemoryFileSystem) ReadFile(name string) ([]byte, bool) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	data, ok := fs.files[filepath.Clean(name)]
+	return data, ok
+}
+
+// FileNames returns names of all files written to fs
+func (fs *MemoryFileSystem) FileNames() []string {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	names := []string{}
+	for name := range fs.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// writeFileAtomically writes data to a temporary file in the same directory
+// and renames it into place, so readers never observe a partially written file
+func writeFileAtomically(filename string, data []byte, perm os.FileMode) error {
+	dir, base := filepath.Split(filename)
+	if dir == "" {
+		dir = "."
+	}
+
+	tmp, err := ioutil.TempFile(dir, "."+base+".")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	if err := os.Rename(tmpName, filename); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	yamlv2 "gopkg.in/yaml.v2"
+)
+
+// flowStyler makes sequences render in YAML flow style, e.g. `args: [sh, -c, date]`;
+// the YAML encoder has no way of doing this for generic values, so each of such
+// sequences is replaced with a placeholder, which is substituted after encoding
+type flowStyler struct {
+	patterns        []fieldPathPattern
+	scalarSequences bool
+	sequences       map[string]string
+}
+
+func newFlowStyler(opts EncodeOptions) (*flowStyler, error) {
+	patterns, err := parseFieldPathPatterns(opts.FlowStyle)
+	if err != nil {
+		return nil, err
+	}
+	return &flowStyler{
+		patterns:        patterns,
+		scalarSequences: opts.FlowStyleForScalarSequences,
+		sequences:       make(map[string]string),
+	}, nil
+}
+
+func (f *flowStyler) enabled() bool {
+	return len(f.patterns) != 0 || f.scalarSequences
+}
+
+func (f *flowStyler) matches(path fieldPath, sequence []interface{}) bool {
+	if len(sequence) == 0 {
+		return false
+	}
+	for _, x := range sequence {
+		switch x.(type) {
+		case map[string]interface{}, []interface{}:
+			return false
+		}
+	}
+	if f.scalarSequences {
+		return true
+	}
+	for _, pattern := range f.patterns {
+		if pattern.matches(path) {
+			return true
+		}
+	}
+	return false
+}
+
+func flowScalar(x interface{}) (string, error) {
+	if s, ok := x.(string); ok {
+		data, err := yamlv2.Marshal(s)
+		if err != nil {
+			return "", err
+		}
+		// plain and single-quoted scalars are fine as long as they fit on one line and
+		// have no flow indicators or colons (which a plain scalar may not contain in flow
+		// context), anything else is written as a JSON string
+		if plain := strings.TrimSuffix(string(data), "\n"); !strings.ContainsAny(plain, "\n,[]{}:") && !strings.HasPrefix(plain, "|") && !strings.HasPrefix(plain, ">") {
+			return plain, nil
+		}
+	}
+	data, err := json.Marshal(x)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (f *flowStyler) placeholderFor(sequence []interface{}) (string, error) {
+	elements := make([]string, len(sequence))
+	for n, x := range sequence {
+		element, err := flowScalar(x)
+		if err != nil {
+			return "", err
+		}
+		elements[n] = element
+	}
+	placeholder := fmt.Sprintf("__kubegen_flow_sequence_%d__", len(f.sequences))
+	f.sequences[placeholder] = "[" + strings.Join(elements, ", ") + "]"
+	return placeholder, nil
+}
+
+func (f *flowStyler) replaceSequences(obj map[string]interface{}, at fieldPath) error {
+	for key, value := range obj {
+		path := at.key(key)
+		switch value := value.(type) {
+		case map[string]interface{}:
+			if err := f.replaceSequences(value, path); err != nil {
+				return err
+			}
+		case []interface{}:
+			if f.matches(path, value) {
+				placeholder, err := f.placeholderFor(value)
+				if err != nil {
+					return err
+				}
+				obj[key] = placeholder
+				continue
+			}
+			for n, x := range value {
+				if x, ok := x.(map[string]interface{}); ok {
+					if err := f.replaceSequences(x, path.index(n)); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// apply replaces sequences within the object, or each of the items of a list,
+// as paths are relative to each object
+func (f *flowStyler) apply(obj map[string]interface{}) error {
+	items, isList := obj["items"].([]interface{})
+	if !isList {
+		return f.replaceSequences(obj, fieldPath{})
+	}
+	for _, item := range items {
+		if item, ok := item.(map[string]interface{}); ok {
+			if err := f.replaceSequences(item, fieldPath{}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// restore substitutes placeholders in encoded output with flow sequences
+func (f *flowStyler) restore(output []byte) []byte {
+	for placeholder, sequence := range f.sequences {
+		output = bytes.Replace(output, []byte(placeholder), []byte(sequence), 1)
+	}
+	return output
+}
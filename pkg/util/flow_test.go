@@ -0,0 +1,80 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	yamlv2 "gopkg.in/yaml.v2"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// flowTestStrings are awkward to write in flow style, as most of them
+// would either break a flow sequence or change meaning if written plain
+var flowTestStrings = []string{
+	"plain", "a,b", "x]", "[y", "{z}", "k: v", "a:b", "http://example.com:80/",
+	`say "hi"`, "it's", "'quoted'", `"quoted"`, "  leading spaces", "trailing spaces  ",
+	"#hash", "a #b", "-", "- x", "-x", "?", "? x", ":", "multi\nline", "tab\there", "",
+	"true", "no", "1", "0x1F", "1e3", "null", "~", "*ref", "&anchor", "!tag", "%x", "@x",
+	"`x`", "|", ">", "back\\slash", "unicode ✓",
+}
+
+func TestFlowStyleRoundTrip(t *testing.T) {
+	pod := &corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:    "web",
+				Image:   "busybox",
+				Command: []string{"sh", "-c"},
+				Args:    flowTestStrings,
+			}},
+		},
+	}
+
+	for name, opts := range map[string]EncodeOptions{
+		"scalar sequences": {FlowStyleForScalarSequences: true},
+		"paths":            {FlowStyle: []string{"spec.containers[*].args", "spec.containers[0].command"}},
+	} {
+		data, err := EncodeWithOptions(pod, "application/yaml", opts)
+		if !assert.NoError(t, err, name) {
+			continue
+		}
+		assert.Contains(t, string(data), "command: [sh, -c]", name)
+
+		object, err := Decode(data)
+		if !assert.NoError(t, err, "%s:\n%s", name, data) {
+			continue
+		}
+		decoded, ok := object.(*corev1.Pod)
+		if assert.True(t, ok, name) {
+			assert.Equal(t, pod.Spec, decoded.Spec, "%s:\n%s", name, data)
+		}
+	}
+}
+
+func TestFlowScalar(t *testing.T) {
+	for _, s := range flowTestStrings {
+		scalar, err := flowScalar(s)
+		if !assert.NoError(t, err, s) {
+			continue
+		}
+		sequence := "[" + scalar + ", " + scalar + "]"
+
+		var decoded []interface{}
+		if assert.NoError(t, yamlv2.Unmarshal([]byte("x: "+sequence), &struct {
+			X *[]interface{} `yaml:"x"`
+		}{&decoded}), sequence) {
+			assert.Equal(t, []interface{}{s, s}, decoded, sequence)
+		}
+	}
+
+	for x, expected := range map[interface{}]string{1: "1", 1.5: "1.5", true: "true", nil: "null"} {
+		scalar, err := flowScalar(x)
+		if assert.NoError(t, err) {
+			assert.Equal(t, expected, scalar)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+func isGzipped(data []byte) bool {
+	return bytes.HasPrefix(data, gzipMagic)
+}
+
+// NewDecompressingReader returns a reader that transparently decompresses
+// gzipped input, anything else is passed through as is
+func NewDecompressingReader(r io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(r)
+	magic, err := buffered.Peek(len(gzipMagic))
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("kubegen/util: error reading input – %v", err)
+	}
+	if !isGzipped(magic) {
+		return buffered, nil
+	}
+	gz, err := gzip.NewReader(buffered)
+	if err != nil {
+		return nil, fmt.Errorf("kubegen/util: error decompressing input – %v", err)
+	}
+	return gz, nil
+}
+
+// maybeGunzip decompresses data if it's gzipped, it also strips ".gz"
+// extension from sourcePath, so that the format can be determined
+func maybeGunzip(data []byte, sourcePath string) ([]byte, string, error) {
+	sourcePath = strings.TrimSuffix(sourcePath, ".gz")
+	if !isGzipped(data) {
+		return data, sourcePath, nil
+	}
+	r, err := NewDecompressingReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, sourcePath, err
+	}
+	if data, err = ioutil.ReadAll(r); err != nil {
+		return nil, sourcePath, fmt.Errorf("kubegen/util: error decompressing input – %v", err)
+	}
+	return data, sourcePath, nil
+}
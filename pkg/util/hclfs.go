@@ -0,0 +1,19 @@
+//go:build go1.16
+// +build go1.16
+
+package util
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+// NewFromHCLFS is like NewFromHCL, but reads the manifest from fsys,
+// e.g. an embed.FS with manifests that ship inside of the binary
+func NewFromHCLFS(obj interface{}, fsys fs.FS, path string) error {
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return fmt.Errorf("kubegen/util: error reading HCL manifest %q – %v", path, err)
+	}
+	return NewFromHCL(obj, data)
+}
@@ -0,0 +1,90 @@
+package util
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl"
+)
+
+// variableReference matches `${var.<name>}`, and `$${var.<name>}` which is an escaped reference
+var variableReference = regexp.MustCompile(`\$?\$\{var\.([A-Za-z_][A-Za-z0-9_-]*)\}`)
+
+// LoadHCLVars reads variable definitions (`name = value`) from a vars file,
+// only string, number and boolean values are supported
+func LoadHCLVars(varsPath string) (map[string]string, error) {
+	data, err := ioutil.ReadFile(varsPath)
+	if err != nil {
+		return nil, fmt.Errorf("kubegen/util: error reading vars file – %v", err)
+	}
+
+	values := make(map[string]interface{})
+	if err := hcl.Decode(&values, string(data)); err != nil {
+		return nil, fmt.Errorf("kubegen/util: error parsing vars file %q as HCL – %v", varsPath, err)
+	}
+
+	vars := make(map[string]string, len(values))
+	for k, v := range values {
+		switch v := v.(type) {
+		case string:
+			vars[k] = v
+		case int, int64, float64, bool:
+			vars[k] = fmt.Sprint(v)
+		default:
+			return nil, fmt.Errorf("kubegen/util: error loading vars file %q – variable %q is not a string, a number or a boolean", varsPath, k)
+		}
+	}
+
+	return vars, nil
+}
+
+// interpolateVars replaces all `${var.<name>}` references in manifest, the
+// values are escaped, as references are expected only within quoted strings
+func interpolateVars(manifest []byte, vars map[string]string) ([]byte, error) {
+	missing := map[string]bool{}
+
+	result := variableReference.ReplaceAllFunc(manifest, func(ref []byte) []byte {
+		if strings.HasPrefix(string(ref), "$$") {
+			return ref[1:]
+		}
+		name := string(variableReference.FindSubmatch(ref)[1])
+		value, ok := vars[name]
+		if !ok {
+			missing[name] = true
+			return ref
+		}
+		quoted := strconv.Quote(value)
+		return []byte(quoted[1 : len(quoted)-1])
+	})
+
+	if len(missing) > 0 {
+		names := []string{}
+		for name := range missing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("kubegen/util: undefined variables referenced in the manifest – %s", strings.Join(names, ", "))
+	}
+
+	return result, nil
+}
+
+// NewFromHCLWithVarsFile is like NewFromHCL, but first it replaces all
+// `${var.<name>}` references in manifest with values defined in a vars file
+func NewFromHCLWithVarsFile(obj interface{}, manifest []byte, varsPath string) error {
+	vars, err := LoadHCLVars(varsPath)
+	if err != nil {
+		return err
+	}
+
+	data, err := interpolateVars(manifest, vars)
+	if err != nil {
+		return err
+	}
+
+	return NewFromHCL(obj, data)
+}
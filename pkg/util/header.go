@@ -0,0 +1,65 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DefaultHeaderTemplate is used when DumpOptions.HeaderTemplate is empty
+const DefaultHeaderTemplate = `# generated by kubegen
+# => {{ .Filename }}
+{{ if .Source }}# source: {{ .Source }}
+{{ end }}{{ if not .OmitDocumentSeparator }}---
+{{ end }}`
+
+// HeaderData is what the header template gets executed with
+type HeaderData struct {
+	Filename  string
+	Kind      string
+	Name      string
+	Namespace string
+	// Now is the time of writing the file, in UTC
+	Now time.Time
+
+	// Source and OmitDocumentSeparator are copied from DumpOptions
+	Source                string
+	OmitDocumentSeparator bool
+}
+
+func renderHeader(object runtime.Object, basename string, opts DumpOptions) ([]byte, error) {
+	text := opts.HeaderTemplate
+	if text == "" {
+		text = DefaultHeaderTemplate
+	}
+
+	tmpl, err := template.New("header").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("kubegen/util: error parsing header template – %v", err)
+	}
+
+	data := HeaderData{
+		Filename:              basename,
+		Now:                   now().UTC(),
+		Source:                opts.Source,
+		OmitDocumentSeparator: opts.OmitDocumentSeparator,
+	}
+	// object is nil for files that hold more than one object
+	if object != nil {
+		data.Kind = object.GetObjectKind().GroupVersionKind().Kind
+		if objectMeta, err := meta.Accessor(object); err == nil {
+			data.Name = objectMeta.GetName()
+			data.Namespace = objectMeta.GetNamespace()
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, data); err != nil {
+		return nil, fmt.Errorf("kubegen/util: error rendering header of %q – %v", basename, err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,157 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	yamlv2 "gopkg.in/yaml.v2"
+)
+
+// KeyOrder determines how keys of each mapping are ordered in encoded output
+type KeyOrder int
+
+const (
+	// Alphabetical sorts all keys, it's the default
+	Alphabetical KeyOrder = iota
+	// Canonical puts apiVersion, kind and metadata first, and keeps all other keys
+	// in the order of fields of the Go types (which is what kubectl does)
+	Canonical
+	// AsIs keeps keys in the order of fields of the Go types
+	AsIs
+)
+
+var canonicalTopLevelKeys = []string{"apiVersion", "kind", "metadata"}
+
+// loadKeyOrderReference parses the original encoding of an object, so that
+// the order of keys can be restored after the object has been cleaned up
+func loadKeyOrderReference(input []byte) interface{} {
+	reference := yamlv2.MapSlice{}
+	if err := yamlv2.Unmarshal(input, &reference); err != nil {
+		return nil
+	}
+	return reference
+}
+
+func lookupKeyOrderReference(reference interface{}, key string) interface{} {
+	if reference, ok := reference.(yamlv2.MapSlice); ok {
+		for _, item := range reference {
+			if k, ok := item.Key.(string); ok && k == key {
+				return item.Value
+			}
+		}
+	}
+	return nil
+}
+
+func orderKeys(obj map[string]interface{}, reference interface{}, order KeyOrder, isObject bool) []string {
+	keys := []string{}
+	seen := make(map[string]bool, len(obj))
+	add := func(key string) {
+		if _, ok := obj[key]; ok && !seen[key] {
+			keys = append(keys, key)
+			seen[key] = true
+		}
+	}
+
+	if order == Canonical && isObject {
+		for _, key := range canonicalTopLevelKeys {
+			add(key)
+		}
+	}
+	if reference, ok := reference.(yamlv2.MapSlice); ok {
+		for _, item := range reference {
+			if key, ok := item.Key.(string); ok {
+				add(key)
+			}
+		}
+	}
+
+	// anything not found in the reference goes last
+	rest := []string{}
+	for key := range obj {
+		if !seen[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+
+	return append(keys, rest...)
+}
+
+func writeOrderedJSON(buf *bytes.Buffer, value interface{}, reference interface{}, order KeyOrder, isObject bool) error {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		buf.WriteByte('{')
+		for n, key := range orderKeys(value, reference, order, isObject) {
+			if n > 0 {
+				buf.WriteByte(',')
+			}
+			k, err := json.Marshal(key)
+			if err != nil {
+				return err
+			}
+			buf.Write(k)
+			buf.WriteByte(':')
+			// items of a list are objects in their own right
+			isItems := isObject && key == "items"
+			if err := writeOrderedJSON(buf, value[key], lookupKeyOrderReference(reference, key), order, isItems); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case []interface{}:
+		referenceItems, _ := reference.([]interface{})
+		buf.WriteByte('[')
+		for n, item := range value {
+			if n > 0 {
+				buf.WriteByte(',')
+			}
+			var itemReference interface{}
+			if n < len(referenceItems) {
+				itemReference = referenceItems[n]
+			}
+			if err := writeOrderedJSON(buf, item, itemReference, order, isObject); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+	}
+	return nil
+}
+
+// marshalOrderedJSON encodes obj with keys ordered according to the reference
+func marshalOrderedJSON(obj map[string]interface{}, reference interface{}, order KeyOrder, pretty bool) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := writeOrderedJSON(buf, obj, reference, order, true); err != nil {
+		return nil, err
+	}
+	if !pretty {
+		return buf.Bytes(), nil
+	}
+	indented := &bytes.Buffer{}
+	if err := json.Indent(indented, buf.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	return indented.Bytes(), nil
+}
+
+// marshalOrderedYAML encodes obj with keys ordered according to the reference,
+// it converts ordered JSON to YAML, just like github.com/ghodss/yaml does
+func marshalOrderedYAML(obj map[string]interface{}, reference interface{}, order KeyOrder) ([]byte, error) {
+	data, err := marshalOrderedJSON(obj, reference, order, false)
+	if err != nil {
+		return nil, err
+	}
+	ordered := yamlv2.MapSlice{}
+	if err := yamlv2.Unmarshal(data, &ordered); err != nil {
+		return nil, err
+	}
+	return yamlv2.Marshal(ordered)
+}
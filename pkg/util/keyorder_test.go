@@ -0,0 +1,150 @@
+package util
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	yamlv2 "gopkg.in/yaml.v2"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// keysAt returns keys of the mapping at path within encoded data, in the order they appear
+func keysAt(t *testing.T, data []byte, path ...interface{}) []string {
+	root := yamlv2.MapSlice{}
+	if err := yamlv2.Unmarshal(data, &root); err != nil {
+		t.Fatal(err)
+	}
+	var value interface{} = root
+	for _, elem := range path {
+		switch elem := elem.(type) {
+		case string:
+			value = lookupKeyOrderReference(value, elem)
+		case int:
+			value = value.([]interface{})[elem]
+		}
+	}
+	keys := []string{}
+	for _, item := range value.(yamlv2.MapSlice) {
+		keys = append(keys, item.Key.(string))
+	}
+	return keys
+}
+
+func TestKeyOrder(t *testing.T) {
+	replicas := int32(1)
+	deployment := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Labels: map[string]string{"b": "2", "a": "1"}},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:  "web",
+						Image: "nginx",
+						Args:  []string{"-g", "daemon off;"},
+						Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 80}},
+					}},
+				},
+			},
+		},
+	}
+
+	container := []interface{}{"spec", "template", "spec", "containers", 0}
+
+	for _, contentType := range []string{"application/yaml", "application/json"} {
+		data, err := EncodeWithOptions(deployment, contentType, EncodeOptions{KeyOrder: Alphabetical})
+		if assert.NoError(t, err) {
+			assert.Equal(t, []string{"apiVersion", "kind", "metadata", "spec"}, keysAt(t, data), contentType)
+			assert.Equal(t, []string{"args", "image", "name", "ports"}, keysAt(t, data, container...), contentType)
+		}
+
+		data, err = EncodeWithOptions(deployment, contentType, EncodeOptions{KeyOrder: Canonical})
+		if assert.NoError(t, err) {
+			assert.Equal(t, []string{"apiVersion", "kind", "metadata", "spec"}, keysAt(t, data), contentType)
+			assert.Equal(t, []string{"name", "namespace", "labels"}, keysAt(t, data, "metadata"), contentType)
+			assert.Equal(t, []string{"replicas", "selector", "template"}, keysAt(t, data, "spec"), contentType)
+			assert.Equal(t, []string{"name", "image", "args", "ports"}, keysAt(t, data, container...), contentType)
+			assert.Equal(t, []string{"name", "containerPort"}, keysAt(t, data, append(container, "ports", 0)...), contentType)
+			// map keys have no order of their own, so they are sorted
+			assert.Equal(t, []string{"a", "b"}, keysAt(t, data, "metadata", "labels"), contentType)
+		}
+
+		data, err = EncodeWithOptions(deployment, contentType, EncodeOptions{KeyOrder: AsIs})
+		if assert.NoError(t, err) {
+			assert.Equal(t, []string{"kind", "apiVersion", "metadata", "spec"}, keysAt(t, data), contentType)
+			assert.Equal(t, []string{"name", "image", "args", "ports"}, keysAt(t, data, container...), contentType)
+		}
+	}
+}
+
+func TestMarshalOrdered(t *testing.T) {
+	assert := assert.New(t)
+
+	input := []byte(`{
+  "kind": "List",
+  "items": [
+    {"spec": {"z": 1, "y": [{"b": 1, "a": 2}]}, "kind": "Pod", "metadata": {"name": "x"}, "apiVersion": "v1"}
+  ],
+  "apiVersion": "v1"
+}`)
+	reference := loadKeyOrderReference(input)
+
+	obj := map[string]interface{}{}
+	if err := json.Unmarshal(input, &obj); err != nil {
+		t.Fatal(err)
+	}
+	// keys that aren't in the reference, e.g. added by cleanup, go last in alphabetical order
+	obj["metadata"] = map[string]interface{}{}
+	item := obj["items"].([]interface{})[0].(map[string]interface{})
+	item["status"] = map[string]interface{}{"phase": "Pending"}
+	item["data"] = "extra"
+
+	for order, expected := range map[KeyOrder]string{
+		AsIs:      `{"kind":"List","items":[{"spec":{"z":1,"y":[{"b":1,"a":2}]},"kind":"Pod","metadata":{"name":"x"},"apiVersion":"v1","data":"extra","status":{"phase":"Pending"}}],"apiVersion":"v1","metadata":{}}`,
+		Canonical: `{"apiVersion":"v1","kind":"List","metadata":{},"items":[{"apiVersion":"v1","kind":"Pod","metadata":{"name":"x"},"spec":{"z":1,"y":[{"b":1,"a":2}]},"data":"extra","status":{"phase":"Pending"}}]}`,
+	} {
+		data, err := marshalOrderedJSON(obj, reference, order, false)
+		if assert.NoError(err) {
+			assert.Equal(expected, string(data))
+		}
+	}
+
+	data, err := marshalOrderedYAML(obj, reference, Canonical)
+	if assert.NoError(err) {
+		assert.Equal(`apiVersion: v1
+kind: List
+metadata: {}
+items:
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: x
+  spec:
+    z: 1
+    "y":
+    - b: 1
+      a: 2
+  data: extra
+  status:
+    phase: Pending
+`, string(data))
+	}
+
+	data, err = marshalOrderedJSON(obj, reference, Canonical, true)
+	if assert.NoError(err) {
+		assert.Equal([]string{"apiVersion", "kind", "metadata", "items"}, keysAt(t, data))
+	}
+
+	// without a reference, keys are sorted
+	data, err = marshalOrderedJSON(map[string]interface{}{"b": 1, "a": 2, "kind": "X"}, nil, Canonical, false)
+	if assert.NoError(err) {
+		assert.Equal(`{"kind":"X","a":2,"b":1}`, string(data))
+	}
+}
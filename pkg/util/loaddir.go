@@ -0,0 +1,61 @@
+package util
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// manifestExtensions are what LoadDir reads, gzipped files with these extensions are read as well
+var manifestExtensions = map[string]bool{
+	".yaml": true,
+	".yml":  true,
+	".json": true,
+}
+
+// LoadDir decodes all YAML and JSON files in dir and its subdirectories into one list,
+// files are read in lexical order and may contain multiple documents; other files
+// and hidden directories are skipped
+func LoadDir(dir string) (*metav1.List, error) {
+	list := &metav1.List{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "List",
+			APIVersion: "v1",
+		},
+	}
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return fmt.Errorf("kubegen/util: error reading %q – %v", path, err)
+		}
+		if info.IsDir() {
+			if path != dir && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !manifestExtensions[filepath.Ext(strings.TrimSuffix(path, ".gz"))] {
+			return nil
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("kubegen/util: error reading %q – %v", path, err)
+		}
+		objects, err := DecodeAll(data)
+		if err != nil {
+			return fmt.Errorf("kubegen/util: error decoding %q – %v", path, err)
+		}
+		list.Items = append(list.Items, objects.Items...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
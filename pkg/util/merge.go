@@ -0,0 +1,66 @@
+package util
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MergeOptions control how MergeListsWithOptions handles duplicates
+type MergeOptions struct {
+	// KeepLast makes a later object replace an earlier one with the same
+	// identity, instead of failing; the replacement keeps the original position
+	KeepLast bool
+}
+
+// identityOf returns group, version, kind, namespace and name of the object
+func identityOf(object runtime.Object) string {
+	gvk := object.GetObjectKind().GroupVersionKind()
+	id := gvk.GroupVersion().String() + "/" + gvk.Kind
+	if objectMeta, err := meta.Accessor(object); err == nil {
+		id += "/" + objectMeta.GetNamespace() + "/" + objectMeta.GetName()
+	}
+	return id
+}
+
+// MergeLists concatenates items of all of the lists, it fails when
+// there are two objects of the same kind with the same name
+func MergeLists(lists ...*metav1.List) (*metav1.List, error) {
+	return MergeListsWithOptions(MergeOptions{}, lists...)
+}
+
+func MergeListsWithOptions(opts MergeOptions, lists ...*metav1.List) (*metav1.List, error) {
+	merged := &metav1.List{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "List",
+			APIVersion: "v1",
+		},
+	}
+
+	positions := make(map[string]int)
+	for _, list := range lists {
+		if list == nil {
+			continue
+		}
+		for _, item := range list.Items {
+			if item.Object == nil {
+				continue
+			}
+			id := identityOf(item.Object)
+			if n, ok := positions[id]; ok {
+				if !opts.KeepLast {
+					return nil, fmt.Errorf("kubegen/util: error merging lists – duplicate object %s", describeObject(item.Object))
+				}
+				merged.Items[n] = item
+				continue
+			}
+			positions[id] = len(merged.Items)
+			merged.Items = append(merged.Items, item)
+		}
+	}
+
+	return merged, nil
+}
@@ -0,0 +1,26 @@
+package util
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kubernetes/pkg/api/legacyscheme"
+)
+
+// Normalize encodes object (which applies the cleanup) and decodes the result,
+// so that two objects that would be encoded identically are also deeply equal;
+// fields set by the API server are dropped, as objects dumped from a cluster
+// always have these populated; defaults of Kubernetes are applied to the decoded
+// object, so that a field set to its default value (e.g. `protocol: TCP`)
+// compares equal to one that is left unset
+func Normalize(object runtime.Object, contentType string) (runtime.Object, error) {
+	opts := EncodeOptions{Cleanup: CleanupOptions{StripServerFields: true}}
+	data, err := EncodeWithOptions(object, contentType, opts)
+	if err != nil {
+		return nil, err
+	}
+	normalized, err := Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	legacyscheme.Scheme.Default(normalized)
+	return normalized, nil
+}
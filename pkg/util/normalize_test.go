@@ -0,0 +1,54 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNormalize(t *testing.T) {
+	assert := assert.New(t)
+
+	generated := &corev1.Service{
+		TypeMeta:   metav1.TypeMeta{Kind: "Service", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: map[string]string{}},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"app": "web"},
+			Ports:    []corev1.ServicePort{{Port: 80}},
+		},
+	}
+	dumped := generated.DeepCopy()
+	dumped.Labels = nil
+	dumped.UID = "5f2d3e2a-0b1c-11e8-8a3c-42010a800002"
+	dumped.ResourceVersion = "1234"
+	dumped.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "10.0.0.1"}}
+
+	for _, contentType := range []string{"application/yaml", "application/json"} {
+		a, err := Normalize(generated, contentType)
+		if !assert.NoError(err) {
+			continue
+		}
+		b, err := Normalize(dumped, contentType)
+		if !assert.NoError(err) {
+			continue
+		}
+		assert.Equal(a, b, contentType)
+
+		// defaults are applied
+		service := a.(*corev1.Service)
+		assert.Equal(corev1.ServiceTypeClusterIP, service.Spec.Type, contentType)
+		assert.Equal(corev1.ServiceAffinityNone, service.Spec.SessionAffinity, contentType)
+		assert.Equal(corev1.ProtocolTCP, service.Spec.Ports[0].Protocol, contentType)
+
+		explicit := generated.DeepCopy()
+		explicit.Spec.Type = corev1.ServiceTypeClusterIP
+		explicit.Spec.Ports[0].Protocol = corev1.ProtocolTCP
+		c, err := Normalize(explicit, contentType)
+		if assert.NoError(err) {
+			assert.Equal(a, c, "%s: explicit defaults must equal unset ones", contentType)
+		}
+	}
+}
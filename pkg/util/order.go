@@ -0,0 +1,204 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OrderMode determines the order in which objects are written to a single file
+type OrderMode int
+
+const (
+	// OrderAsIs keeps objects in the order of the list
+	OrderAsIs OrderMode = iota
+	// OrderApply puts objects that others depend on first, e.g. Namespaces and
+	// ConfigMaps go before Deployments, objects of the same kind keep their order
+	OrderApply
+	// OrderSorted sorts objects by kind, then namespace and name
+	OrderSorted
+	// OrderServicesFirst is like OrderApply, but puts Services ahead of anything
+	// other than Namespaces, so that DNS names exist by the time pods start
+	OrderServicesFirst
+	// OrderApplySorted is like OrderApply, but objects of the same kind are sorted by
+	// namespace and name; kinds that OrderApply doesn't know are sorted by kind first
+	OrderApplySorted
+)
+
+// applyOrder lists kinds in the order they should be created in,
+// any other kinds go last, in the order they appear in the list
+var applyOrder = []string{
+	"Namespace",
+	"ResourceQuota",
+	"LimitRange",
+	"PodSecurityPolicy",
+	"PodDisruptionBudget",
+	"ServiceAccount",
+	"Secret",
+	"ConfigMap",
+	"StorageClass",
+	"PersistentVolume",
+	"PersistentVolumeClaim",
+	"CustomResourceDefinition",
+	"ClusterRole",
+	"ClusterRoleBinding",
+	"Role",
+	"RoleBinding",
+	"Service",
+	"DaemonSet",
+	"Pod",
+	"ReplicationController",
+	"ReplicaSet",
+	"Deployment",
+	"HorizontalPodAutoscaler",
+	"StatefulSet",
+	"Job",
+	"CronJob",
+	"Ingress",
+	"APIService",
+}
+
+func applyPriorityOf(kind string) int {
+	for n, k := range applyOrder {
+		if k == kind {
+			return n
+		}
+	}
+	return len(applyOrder)
+}
+
+func servicesFirstPriorityOf(kind string) int {
+	switch kind {
+	case "Namespace":
+		return -2
+	case "Service":
+		return -1
+	}
+	return applyPriorityOf(kind)
+}
+
+func sortKeyOf(object runtime.Object) (kind, namespace, name string) {
+	kind = object.GetObjectKind().GroupVersionKind().Kind
+	if objectMeta, err := meta.Accessor(object); err == nil {
+		namespace, name = objectMeta.GetNamespace(), objectMeta.GetName()
+	}
+	return kind, namespace, name
+}
+
+// orderedItems returns items of the list that hold decoded objects, in the given order
+func orderedItems(list *metav1.List, order OrderMode) []runtime.Object {
+	objects := []runtime.Object{}
+	rangeOverObjects(list, func(object runtime.Object) {
+		objects = append(objects, object)
+	})
+
+	byPriority := func(priorityOf func(string) int) {
+		sort.SliceStable(objects, func(i, j int) bool {
+			ki, _, _ := sortKeyOf(objects[i])
+			kj, _, _ := sortKeyOf(objects[j])
+			return priorityOf(ki) < priorityOf(kj)
+		})
+	}
+
+	switch order {
+	case OrderApply:
+		byPriority(applyPriorityOf)
+	case OrderServicesFirst:
+		byPriority(servicesFirstPriorityOf)
+	case OrderApplySorted:
+		sort.SliceStable(objects, func(i, j int) bool {
+			ki, nsi, ni := sortKeyOf(objects[i])
+			kj, nsj, nj := sortKeyOf(objects[j])
+			if pi, pj := applyPriorityOf(ki), applyPriorityOf(kj); pi != pj {
+				return pi < pj
+			}
+			if ki != kj {
+				return ki < kj
+			}
+			if nsi != nsj {
+				return nsi < nsj
+			}
+			return ni < nj
+		})
+	case OrderSorted:
+		sort.SliceStable(objects, func(i, j int) bool {
+			ki, nsi, ni := sortKeyOf(objects[i])
+			kj, nsj, nj := sortKeyOf(objects[j])
+			if ki != kj {
+				return ki < kj
+			}
+			if nsi != nsj {
+				return nsi < nsj
+			}
+			return ni < nj
+		})
+	}
+
+	return objects
+}
+
+// DumpListToFileOrdered writes all objects to a single file, in the given order;
+// YAML documents are separated by "---" and preceded by the usual header,
+// JSON is written as a List
+func DumpListToFileOrdered(list *metav1.List, contentType, filename string, order OrderMode) error {
+	return DumpListToFileOrderedWithOptions(list, contentType, filename, order, DumpOptions{})
+}
+
+// DumpListToFileOrderedWithOptions is like DumpListToFileOrdered, but writes the file
+// to opts.FS and renders the header according to opts; filename is used as it is,
+// so Dir and GroupByNamespace have no effect
+func DumpListToFileOrderedWithOptions(list *metav1.List, contentType, filename string, order OrderMode, opts DumpOptions) error {
+	fs := opts.FS
+	if fs == nil {
+		fs = OSFileSystem{}
+	}
+
+	objects := orderedItems(list, order)
+
+	var data []byte
+	switch contentType {
+	case "application/yaml":
+		header, err := renderHeader(nil, path.Base(filename), opts)
+		if err != nil {
+			return err
+		}
+		buf := bytes.NewBuffer(header)
+		for n, object := range objects {
+			doc, err := Encode(object, contentType, true)
+			if err != nil {
+				return err
+			}
+			if n > 0 {
+				buf.WriteString("---\n")
+			}
+			buf.Write(doc)
+		}
+		data = buf.Bytes()
+	case "application/json":
+		ordered := &metav1.List{
+			TypeMeta: list.TypeMeta,
+			ListMeta: list.ListMeta,
+		}
+		for _, object := range objects {
+			ordered.Items = append(ordered.Items, runtime.RawExtension{Object: object})
+		}
+		var err error
+		if data, err = EncodeList(ordered, contentType, true); err != nil {
+			return err
+		}
+	default:
+		_, err := fileExtensionFor(contentType)
+		return err
+	}
+
+	if err := fs.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("kubegen/util: error writing to file %q – %v", filename, err)
+	}
+	return nil
+}
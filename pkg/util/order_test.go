@@ -0,0 +1,59 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDumpListToFileOrderedWithOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	deployment := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+	}
+	service := &corev1.Service{
+		TypeMeta:   metav1.TypeMeta{Kind: "Service", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+	}
+	list := &metav1.List{Items: []runtime.RawExtension{{Object: deployment}, {Object: service}}}
+
+	dir, err := os.Getwd()
+	if !assert.NoError(err) {
+		return
+	}
+
+	for _, contentType := range []string{"application/yaml", "application/json"} {
+		fs := NewMemoryFileSystem()
+		filename := filepath.Join(dir, "all."+strings.TrimPrefix(contentType, "application/"))
+
+		err := DumpListToFileOrderedWithOptions(list, contentType, filename, OrderServicesFirst, DumpOptions{FS: fs, Source: "web.kg"})
+		if !assert.NoError(err, contentType) {
+			continue
+		}
+
+		_, err = os.Stat(filename)
+		assert.True(os.IsNotExist(err), "%s: file must not be written to the local filesystem", contentType)
+
+		data, ok := fs.ReadFile(filename)
+		if !assert.True(ok, contentType) {
+			continue
+		}
+		output := string(data)
+		servicesAt, deploymentsAt := strings.Index(output, "Service"), strings.Index(output, "Deployment")
+		assert.True(servicesAt >= 0 && deploymentsAt > servicesAt, "%s: services must go first", contentType)
+		if contentType == "application/yaml" {
+			assert.Contains(output, "web.kg", "header must be rendered with the options")
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+
+	"github.com/hashicorp/hcl"
+)
+
+// collapseHCLBlocks turns lists of maps that HCL produces for blocks into plain maps,
+// a list is only kept where base has a list at the same location, i.e. where objects
+// in the overlay are written as `containers = [{ ... }]` rather than as blocks
+func collapseHCLBlocks(value, base interface{}) interface{} {
+	switch value := value.(type) {
+	case []map[string]interface{}:
+		if _, isList := base.([]interface{}); isList || len(value) != 1 {
+			items := make([]interface{}, len(value))
+			for n, item := range value {
+				items[n] = collapseHCLBlocks(item, nil)
+			}
+			return items
+		}
+		return collapseHCLBlocks(value[0], base)
+	case map[string]interface{}:
+		baseMap, _ := base.(map[string]interface{})
+		for k, v := range value {
+			value[k] = collapseHCLBlocks(v, baseMap[k])
+		}
+		return value
+	case []interface{}:
+		for n, v := range value {
+			value[n] = collapseHCLBlocks(v, nil)
+		}
+		return value
+	default:
+		return value
+	}
+}
+
+// ApplyHCLOverlay decodes overlay as a sparse patch written in HCL, e.g.
+// `spec { replicas = 5 }`, and merges it onto base the same way as
+// `kubectl patch --type=strategic` does, so that containers are merged
+// by name; base has to be a typed object, the result is a new object
+func ApplyHCLOverlay(base runtime.Object, overlay []byte) (runtime.Object, error) {
+	if _, ok := base.(*unstructured.Unstructured); ok {
+		return nil, fmt.Errorf("kubegen/util: unable to apply overlay to %s – strategic merge requires a typed object", describeObject(base))
+	}
+
+	var patch interface{}
+	if err := hcl.Unmarshal(overlay, &patch); err != nil {
+		return nil, fmt.Errorf("kubegen/util: error parsing HCL overlay – %v", err)
+	}
+
+	original, err := json.Marshal(base)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(original, &doc); err != nil {
+		return nil, err
+	}
+
+	patchJSON, err := json.Marshal(collapseHCLBlocks(patch, doc))
+	if err != nil {
+		return nil, fmt.Errorf("kubegen/util: error encoding HCL overlay – %v", err)
+	}
+
+	merged, err := strategicpatch.StrategicMergePatch(original, patchJSON, base)
+	if err != nil {
+		return nil, fmt.Errorf("kubegen/util: error applying overlay to %s – %v", describeObject(base), err)
+	}
+	return Decode(merged)
+}
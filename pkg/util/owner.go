@@ -0,0 +1,58 @@
+package util
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// SetOwnerReference makes owner the controller of owned, so that garbage
+// collection removes owned once owner is deleted. Generated objects don't
+// normally have a UID, in which case the uid field doesn't get encoded.
+func SetOwnerReference(owner, owned runtime.Object) error {
+	ownerMeta, err := meta.Accessor(owner)
+	if err != nil {
+		return fmt.Errorf("kubegen/util: error setting owner reference, owner has no metadata – %v", err)
+	}
+	return SetOwnerReferenceWithUID(owner, owned, ownerMeta.GetUID())
+}
+
+// SetOwnerReferenceWithUID is like SetOwnerReference, but uses the given UID
+// instead of the UID of the owner (which may be empty)
+func SetOwnerReferenceWithUID(owner, owned runtime.Object, uid types.UID) error {
+	gvk := owner.GetObjectKind().GroupVersionKind()
+	if gvk.Kind == "" || gvk.Version == "" {
+		return fmt.Errorf("kubegen/util: error setting owner reference, owner kind or version is not set")
+	}
+
+	ownerMeta, err := meta.Accessor(owner)
+	if err != nil {
+		return fmt.Errorf("kubegen/util: error setting owner reference, owner has no metadata – %v", err)
+	}
+	if ownerMeta.GetName() == "" {
+		return fmt.Errorf("kubegen/util: error setting owner reference, owner %s has no name", gvk.Kind)
+	}
+
+	ownedMeta, err := meta.Accessor(owned)
+	if err != nil {
+		return fmt.Errorf("kubegen/util: error setting owner reference, owned object has no metadata – %v", err)
+	}
+
+	ref := metav1.NewControllerRef(ownerMeta, gvk)
+	ref.UID = uid
+
+	// replace any existing reference to the same owner
+	refs := []metav1.OwnerReference{}
+	for _, existing := range ownedMeta.GetOwnerReferences() {
+		if existing.APIVersion == ref.APIVersion && existing.Kind == ref.Kind && existing.Name == ref.Name {
+			continue
+		}
+		refs = append(refs, existing)
+	}
+	ownedMeta.SetOwnerReferences(append(refs, *ref))
+
+	return nil
+}
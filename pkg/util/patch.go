@@ -0,0 +1,298 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/ghodss/yaml"
+)
+
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q, must begin with \"/\"", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for n, token := range tokens {
+		tokens[n] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, nil
+}
+
+func jsonArrayIndex(token string, length int, allowLength bool) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || (token != "0" && strings.HasPrefix(token, "0")) {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if index > length || (index == length && !allowLength) {
+		return 0, fmt.Errorf("array index %d is out of bounds", index)
+	}
+	return index, nil
+}
+
+func jsonPointerGet(doc interface{}, tokens []string) (interface{}, error) {
+	for _, token := range tokens {
+		switch d := doc.(type) {
+		case map[string]interface{}:
+			v, ok := d[token]
+			if !ok {
+				return nil, fmt.Errorf("key %q not found", token)
+			}
+			doc = v
+		case []interface{}:
+			index, err := jsonArrayIndex(token, len(d), false)
+			if err != nil {
+				return nil, err
+			}
+			doc = d[index]
+		default:
+			return nil, fmt.Errorf("cannot lookup %q in a scalar value", token)
+		}
+	}
+	return doc, nil
+}
+
+// jsonPointerUpdate descends into doc and calls update with the value
+// that contains the last token, the result replaces that value in doc
+func jsonPointerUpdate(doc interface{}, tokens []string, update func(container interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(tokens) == 1 {
+		return update(doc, tokens[0])
+	}
+
+	switch d := doc.(type) {
+	case map[string]interface{}:
+		child, ok := d[tokens[0]]
+		if !ok {
+			return nil, fmt.Errorf("key %q not found", tokens[0])
+		}
+		child, err := jsonPointerUpdate(child, tokens[1:], update)
+		if err != nil {
+			return nil, err
+		}
+		d[tokens[0]] = child
+		return d, nil
+	case []interface{}:
+		index, err := jsonArrayIndex(tokens[0], len(d), false)
+		if err != nil {
+			return nil, err
+		}
+		child, err := jsonPointerUpdate(d[index], tokens[1:], update)
+		if err != nil {
+			return nil, err
+		}
+		d[index] = child
+		return d, nil
+	default:
+		return nil, fmt.Errorf("cannot lookup %q in a scalar value", tokens[0])
+	}
+}
+
+func jsonPatchAdd(doc interface{}, tokens []string, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	return jsonPointerUpdate(doc, tokens, func(container interface{}, token string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			c[token] = value
+			return c, nil
+		case []interface{}:
+			if token == "-" {
+				return append(c, value), nil
+			}
+			index, err := jsonArrayIndex(token, len(c), true)
+			if err != nil {
+				return nil, err
+			}
+			c = append(c, nil)
+			copy(c[index+1:], c[index:])
+			c[index] = value
+			return c, nil
+		default:
+			return nil, fmt.Errorf("cannot add %q to a scalar value", token)
+		}
+	})
+}
+
+func jsonPatchRemove(doc interface{}, tokens []string) (interface{}, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("cannot remove the whole document")
+	}
+	return jsonPointerUpdate(doc, tokens, func(container interface{}, token string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			if _, ok := c[token]; !ok {
+				return nil, fmt.Errorf("key %q not found", token)
+			}
+			delete(c, token)
+			return c, nil
+		case []interface{}:
+			index, err := jsonArrayIndex(token, len(c), false)
+			if err != nil {
+				return nil, err
+			}
+			return append(c[:index], c[index+1:]...), nil
+		default:
+			return nil, fmt.Errorf("cannot remove %q from a scalar value", token)
+		}
+	})
+}
+
+func jsonPatchReplace(doc interface{}, tokens []string, value interface{}) (interface{}, error) {
+	if _, err := jsonPointerGet(doc, tokens); err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	return jsonPointerUpdate(doc, tokens, func(container interface{}, token string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			c[token] = value
+			return c, nil
+		case []interface{}:
+			index, err := jsonArrayIndex(token, len(c), false)
+			if err != nil {
+				return nil, err
+			}
+			c[index] = value
+			return c, nil
+		default:
+			return nil, fmt.Errorf("cannot replace %q in a scalar value", token)
+		}
+	})
+}
+
+func deepCopyJSONValue(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var result interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func applyJSONPatchOperation(doc interface{}, operation map[string]interface{}) (interface{}, error) {
+	op, _ := operation["op"].(string)
+	path, ok := operation["path"].(string)
+	if !ok {
+		return nil, fmt.Errorf("operation %q has no path", op)
+	}
+	tokens, err := parseJSONPointer(path)
+	if err != nil {
+		return nil, err
+	}
+
+	value, hasValue := operation["value"]
+	fromTokens := func() ([]string, error) {
+		from, ok := operation["from"].(string)
+		if !ok {
+			return nil, fmt.Errorf("operation %q has no from path", op)
+		}
+		return parseJSONPointer(from)
+	}
+
+	switch op {
+	case "add", "replace", "test":
+		if !hasValue {
+			return nil, fmt.Errorf("operation %q has no value", op)
+		}
+	}
+
+	switch op {
+	case "add":
+		return jsonPatchAdd(doc, tokens, value)
+	case "remove":
+		return jsonPatchRemove(doc, tokens)
+	case "replace":
+		return jsonPatchReplace(doc, tokens, value)
+	case "move":
+		from, err := fromTokens()
+		if err != nil {
+			return nil, err
+		}
+		if len(tokens) > len(from) && reflect.DeepEqual(tokens[:len(from)], from) {
+			return nil, fmt.Errorf("cannot move a value into one of its children")
+		}
+		v, err := jsonPointerGet(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if doc, err = jsonPatchRemove(doc, from); err != nil {
+			return nil, err
+		}
+		return jsonPatchAdd(doc, tokens, v)
+	case "copy":
+		from, err := fromTokens()
+		if err != nil {
+			return nil, err
+		}
+		v, err := jsonPointerGet(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if v, err = deepCopyJSONValue(v); err != nil {
+			return nil, err
+		}
+		return jsonPatchAdd(doc, tokens, v)
+	case "test":
+		v, err := jsonPointerGet(doc, tokens)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(v, value) {
+			return nil, fmt.Errorf("test failed, value at %q is different", path)
+		}
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("unknown operation %q", op)
+	}
+}
+
+// ApplyJSONPatch applies RFC 6902 JSON Patch operations to object, the patch
+// can be given either as JSON or as YAML (in which case contentType must be
+// "application/yaml"), the result is decoded back into a typed object
+func ApplyJSONPatch(object runtime.Object, patch []byte, contentType string) (runtime.Object, error) {
+	var err error
+
+	if contentType == "application/yaml" {
+		if patch, err = yaml.YAMLToJSON(patch); err != nil {
+			return nil, fmt.Errorf("kubegen/util: error parsing JSON patch – %v", err)
+		}
+	}
+
+	operations := []map[string]interface{}{}
+	if err := json.Unmarshal(patch, &operations); err != nil {
+		return nil, fmt.Errorf("kubegen/util: error parsing JSON patch – %v", err)
+	}
+
+	data, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	for n, operation := range operations {
+		if doc, err = applyJSONPatchOperation(doc, operation); err != nil {
+			return nil, fmt.Errorf("kubegen/util: error applying JSON patch operation #%d – %v", n, err)
+		}
+	}
+
+	if data, err = json.Marshal(doc); err != nil {
+		return nil, err
+	}
+	return Decode(data)
+}
@@ -0,0 +1,236 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newPatchTestPod() *corev1.Pod {
+	return &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "web",
+			Labels: map[string]string{
+				"app":               "web",
+				"example.com/tier":  "frontend",
+				"example.com~owner": "ops",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  "web",
+				Image: "nginx",
+				Args:  []string{"a", "b", "c"},
+			}},
+		},
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	const args = "/spec/containers/0/args"
+
+	tests := []struct {
+		name   string
+		patch  string
+		labels map[string]string
+		args   []string
+		err    bool
+	}{
+		{
+			name:   "add key",
+			patch:  `[{"op": "add", "path": "/metadata/labels/env", "value": "prod"}]`,
+			labels: map[string]string{"app": "web", "example.com/tier": "frontend", "example.com~owner": "ops", "env": "prod"},
+			args:   []string{"a", "b", "c"},
+		},
+		{
+			name:  "add inserts into array",
+			patch: `[{"op": "add", "path": "` + args + `/1", "value": "x"}]`,
+			args:  []string{"a", "x", "b", "c"},
+		},
+		{
+			name:  "add at array length",
+			patch: `[{"op": "add", "path": "` + args + `/3", "value": "x"}]`,
+			args:  []string{"a", "b", "c", "x"},
+		},
+		{
+			name:  "add appends with -",
+			patch: `[{"op": "add", "path": "` + args + `/-", "value": "x"}]`,
+			args:  []string{"a", "b", "c", "x"},
+		},
+		{
+			name:  "add past array length",
+			patch: `[{"op": "add", "path": "` + args + `/4", "value": "x"}]`,
+			err:   true,
+		},
+		{
+			name:  "add with leading zero index",
+			patch: `[{"op": "add", "path": "` + args + `/01", "value": "x"}]`,
+			err:   true,
+		},
+		{
+			name:  "add without value",
+			patch: `[{"op": "add", "path": "/metadata/labels/env"}]`,
+			err:   true,
+		},
+		{
+			name:   "remove key with ~1 escape",
+			patch:  `[{"op": "remove", "path": "/metadata/labels/example.com~1tier"}]`,
+			labels: map[string]string{"app": "web", "example.com~owner": "ops"},
+			args:   []string{"a", "b", "c"},
+		},
+		{
+			name:   "remove key with ~0 escape",
+			patch:  `[{"op": "remove", "path": "/metadata/labels/example.com~0owner"}]`,
+			labels: map[string]string{"app": "web", "example.com/tier": "frontend"},
+			args:   []string{"a", "b", "c"},
+		},
+		{
+			name:  "remove array element",
+			patch: `[{"op": "remove", "path": "` + args + `/0"}]`,
+			args:  []string{"b", "c"},
+		},
+		{
+			name:  "remove out of range",
+			patch: `[{"op": "remove", "path": "` + args + `/3"}]`,
+			err:   true,
+		},
+		{
+			name:  "remove missing key",
+			patch: `[{"op": "remove", "path": "/metadata/labels/env"}]`,
+			err:   true,
+		},
+		{
+			name:   "replace key",
+			patch:  `[{"op": "replace", "path": "/metadata/labels/app", "value": "api"}]`,
+			labels: map[string]string{"app": "api", "example.com/tier": "frontend", "example.com~owner": "ops"},
+			args:   []string{"a", "b", "c"},
+		},
+		{
+			name:  "replace array element",
+			patch: `[{"op": "replace", "path": "` + args + `/2", "value": "x"}]`,
+			args:  []string{"a", "b", "x"},
+		},
+		{
+			name:  "replace out of range",
+			patch: `[{"op": "replace", "path": "` + args + `/3", "value": "x"}]`,
+			err:   true,
+		},
+		{
+			name:  "replace missing key",
+			patch: `[{"op": "replace", "path": "/metadata/labels/env", "value": "prod"}]`,
+			err:   true,
+		},
+		{
+			name:   "move key",
+			patch:  `[{"op": "move", "from": "/metadata/labels/app", "path": "/metadata/labels/name"}]`,
+			labels: map[string]string{"name": "web", "example.com/tier": "frontend", "example.com~owner": "ops"},
+			args:   []string{"a", "b", "c"},
+		},
+		{
+			name:  "move array element",
+			patch: `[{"op": "move", "from": "` + args + `/0", "path": "` + args + `/-"}]`,
+			args:  []string{"b", "c", "a"},
+		},
+		{
+			name:  "move into its own child",
+			patch: `[{"op": "move", "from": "/metadata/labels", "path": "/metadata/labels/app"}]`,
+			err:   true,
+		},
+		{
+			name:  "move without from",
+			patch: `[{"op": "move", "path": "/metadata/labels/name"}]`,
+			err:   true,
+		},
+		{
+			name:   "copy key",
+			patch:  `[{"op": "copy", "from": "/metadata/labels/example.com~1tier", "path": "/metadata/labels/tier"}]`,
+			labels: map[string]string{"app": "web", "example.com/tier": "frontend", "example.com~owner": "ops", "tier": "frontend"},
+			args:   []string{"a", "b", "c"},
+		},
+		{
+			name:  "copy array element",
+			patch: `[{"op": "copy", "from": "` + args + `/2", "path": "` + args + `/0"}]`,
+			args:  []string{"c", "a", "b", "c"},
+		},
+		{
+			name:  "copy from out of range",
+			patch: `[{"op": "copy", "from": "` + args + `/5", "path": "` + args + `/0"}]`,
+			err:   true,
+		},
+		{
+			name: "test passes",
+			patch: `[
+				{"op": "test", "path": "` + args + `/1", "value": "b"},
+				{"op": "replace", "path": "` + args + `/1", "value": "x"}
+			]`,
+			args: []string{"a", "x", "c"},
+		},
+		{
+			name: "test fails",
+			patch: `[
+				{"op": "replace", "path": "` + args + `/1", "value": "x"},
+				{"op": "test", "path": "` + args + `/1", "value": "b"}
+			]`,
+			err: true,
+		},
+		{
+			name:  "test out of range",
+			patch: `[{"op": "test", "path": "` + args + `/3", "value": "c"}]`,
+			err:   true,
+		},
+		{
+			name:  "unknown operation",
+			patch: `[{"op": "merge", "path": "/metadata/labels", "value": {}}]`,
+			err:   true,
+		},
+		{
+			name:  "invalid pointer",
+			patch: `[{"op": "remove", "path": "metadata/labels/app"}]`,
+			err:   true,
+		},
+	}
+
+	for _, test := range tests {
+		pod := newPatchTestPod()
+		result, err := ApplyJSONPatch(pod, []byte(test.patch), "application/json")
+
+		assert.Equal(t, newPatchTestPod(), pod, "%s: input object was modified", test.name)
+
+		if test.err {
+			assert.Error(t, err, test.name)
+			assert.Nil(t, result, test.name)
+			continue
+		}
+		if !assert.NoError(t, err, test.name) {
+			continue
+		}
+		patched, ok := result.(*corev1.Pod)
+		if !assert.True(t, ok, "%s: result is %T, not a pod", test.name, result) {
+			continue
+		}
+
+		expectedLabels := test.labels
+		if expectedLabels == nil {
+			expectedLabels = newPatchTestPod().Labels
+		}
+		assert.Equal(t, expectedLabels, patched.Labels, test.name)
+		assert.Equal(t, test.args, patched.Spec.Containers[0].Args, test.name)
+	}
+}
+
+func TestApplyJSONPatchYAML(t *testing.T) {
+	assert := assert.New(t)
+
+	patch := []byte("- op: add\n  path: /metadata/labels/env\n  value: prod\n")
+	result, err := ApplyJSONPatch(newPatchTestPod(), patch, "application/yaml")
+	if assert.NoError(err) {
+		assert.Equal("prod", result.(*corev1.Pod).Labels["env"])
+	}
+
+	_, err = ApplyJSONPatch(newPatchTestPod(), patch, "application/json")
+	assert.Error(err)
+}
@@ -0,0 +1,157 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// fieldPath is a location of a value within a decoded object, each
+// element is either a map key (string) or a slice index (int)
+type fieldPath []interface{}
+
+func (p fieldPath) key(k string) fieldPath {
+	return append(p[:len(p):len(p)], k)
+}
+
+func (p fieldPath) index(i int) fieldPath {
+	return append(p[:len(p):len(p)], i)
+}
+
+func (p fieldPath) String() string {
+	buf := &bytes.Buffer{}
+	for n, elem := range p {
+		switch elem := elem.(type) {
+		case string:
+			if strings.ContainsAny(elem, ".[]") {
+				fmt.Fprintf(buf, "[%q]", elem)
+				continue
+			}
+			if n > 0 {
+				buf.WriteByte('.')
+			}
+			buf.WriteString(elem)
+		case int:
+			fmt.Fprintf(buf, "[%d]", elem)
+		}
+	}
+	return buf.String()
+}
+
+type pathSegment struct {
+	key      string
+	isIndex  bool
+	anyIndex bool
+	index    int
+}
+
+func (s pathSegment) matches(elem interface{}) bool {
+	switch elem := elem.(type) {
+	case string:
+		return !s.isIndex && s.key == elem
+	case int:
+		return s.isIndex && (s.anyIndex || s.index == elem)
+	}
+	return false
+}
+
+// fieldPathPattern is a parsed path expression, such as
+// `spec.template.spec.containers[*].resources`; `[*]` matches
+// any slice index, `[N]` matches index N, and keys that contain
+// dots can be quoted, e.g. `metadata.annotations["example.com/key"]`
+type fieldPathPattern []pathSegment
+
+func parseFieldPathPattern(s string) (fieldPathPattern, error) {
+	invalid := func(reason string) error {
+		return fmt.Errorf("kubegen/util: invalid field path %q – %s", s, reason)
+	}
+
+	if s == "" {
+		return nil, invalid("path is empty")
+	}
+
+	pattern := fieldPathPattern{}
+	i := 0
+	for i < len(s) {
+		switch s[i] {
+		case '.':
+			if i == 0 || i+1 == len(s) || s[i+1] == '.' || s[i+1] == '[' {
+				return nil, invalid("empty key")
+			}
+			i++
+		case '[':
+			rest := s[i+1:]
+			if strings.HasPrefix(rest, `"`) {
+				end := strings.Index(rest[1:], `"]`)
+				if end < 0 {
+					return nil, invalid("unterminated quoted key")
+				}
+				key, err := strconv.Unquote(rest[:end+2])
+				if err != nil {
+					return nil, invalid(err.Error())
+				}
+				pattern = append(pattern, pathSegment{key: key})
+				i += end + 4
+			} else {
+				end := strings.IndexByte(rest, ']')
+				if end < 0 {
+					return nil, invalid("unterminated index")
+				}
+				if inner := rest[:end]; inner == "*" {
+					pattern = append(pattern, pathSegment{isIndex: true, anyIndex: true})
+				} else {
+					index, err := strconv.Atoi(inner)
+					if err != nil || index < 0 {
+						return nil, invalid(fmt.Sprintf("index %q is neither a wildcard nor a non-negative number", inner))
+					}
+					pattern = append(pattern, pathSegment{isIndex: true, index: index})
+				}
+				i += end + 2
+			}
+			if i < len(s) && s[i] != '.' && s[i] != '[' {
+				return nil, invalid("unexpected characters after closing bracket")
+			}
+		default:
+			end := strings.IndexAny(s[i:], ".[")
+			if end < 0 {
+				end = len(s) - i
+			}
+			pattern = append(pattern, pathSegment{key: s[i : i+end]})
+			i += end
+		}
+	}
+
+	return pattern, nil
+}
+
+func parseFieldPathPatterns(patterns []string) ([]fieldPathPattern, error) {
+	parsed := []fieldPathPattern{}
+	for _, s := range patterns {
+		pattern, err := parseFieldPathPattern(s)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, pattern)
+	}
+	return parsed, nil
+}
+
+// matches checks whether path is exactly what the pattern describes
+func (p fieldPathPattern) matches(path fieldPath) bool {
+	return len(p) == len(path) && p.matchesPrefixOf(path)
+}
+
+// matchesPrefixOf checks whether path is described by the pattern,
+// or whether it is nested inside of a value described by the pattern
+func (p fieldPathPattern) matchesPrefixOf(path fieldPath) bool {
+	if len(p) > len(path) {
+		return false
+	}
+	for n, segment := range p {
+		if !segment.matches(path[n]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFieldPathPattern(t *testing.T) {
+	for s, expected := range map[string]fieldPathPattern{
+		"metadata": {{key: "metadata"}},
+		"spec.template.spec.containers[*].resources": {
+			{key: "spec"}, {key: "template"}, {key: "spec"}, {key: "containers"},
+			{isIndex: true, anyIndex: true}, {key: "resources"},
+		},
+		"spec.ports[0].port": {{key: "spec"}, {key: "ports"}, {isIndex: true, index: 0}, {key: "port"}},
+		"items[12][3]":       {{key: "items"}, {isIndex: true, index: 12}, {isIndex: true, index: 3}},
+		`metadata.annotations["example.com/key"]`: {
+			{key: "metadata"}, {key: "annotations"}, {key: "example.com/key"},
+		},
+		`data["a.b"].c`:      {{key: "data"}, {key: "a.b"}, {key: "c"}},
+		`data["[*]"]`:        {{key: "data"}, {key: "[*]"}},
+		`data["say \"hi\""]`: {{key: "data"}, {key: `say "hi"`}},
+		`["spec"].replicas`:  {{key: "spec"}, {key: "replicas"}},
+	} {
+		pattern, err := parseFieldPathPattern(s)
+		if assert.NoError(t, err, s) {
+			assert.Equal(t, expected, pattern, s)
+		}
+	}
+
+	for _, s := range []string{
+		"",
+		".metadata",
+		"metadata.",
+		"metadata..name",
+		"spec.[0]",
+		"spec.ports[",
+		"spec.ports[0",
+		"spec.ports[-1]",
+		"spec.ports[x]",
+		"spec.ports[]",
+		`data["key`,
+		`data["key"]x`,
+		"spec.ports[0]port",
+	} {
+		_, err := parseFieldPathPattern(s)
+		assert.Error(t, err, s)
+	}
+
+	_, err := parseFieldPathPattern("spec.ports[-1]")
+	assert.Contains(t, err.Error(), "non-negative")
+}
+
+func TestFieldPathPatternMatches(t *testing.T) {
+	assert := assert.New(t)
+
+	parse := func(s string) fieldPathPattern {
+		pattern, err := parseFieldPathPattern(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pattern
+	}
+
+	containers := fieldPath{"spec", "template", "spec", "containers"}
+
+	pattern := parse("spec.template.spec.containers[*].resources")
+	assert.True(pattern.matches(containers.index(0).key("resources")))
+	assert.True(pattern.matches(containers.index(3).key("resources")))
+	assert.False(pattern.matches(containers.index(0)))
+	assert.False(pattern.matches(containers.index(0).key("resources").key("limits")))
+	assert.False(pattern.matches(containers.key("0").key("resources")))
+	assert.True(pattern.matchesPrefixOf(containers.index(0).key("resources").key("limits")))
+	assert.False(pattern.matchesPrefixOf(containers.index(0)))
+
+	pattern = parse("spec.template.spec.containers[1]")
+	assert.True(pattern.matches(containers.index(1)))
+	assert.False(pattern.matches(containers.index(0)))
+	assert.False(pattern.matches(containers.key("1")))
+
+	pattern = parse(`metadata.annotations["example.com/key"]`)
+	assert.True(pattern.matches(fieldPath{"metadata", "annotations", "example.com/key"}))
+	assert.False(pattern.matches(fieldPath{"metadata", "annotations", "example", "com/key"}))
+}
+
+func TestFieldPathString(t *testing.T) {
+	assert := assert.New(t)
+
+	path := fieldPath{"metadata", "annotations", "example.com/key"}
+	assert.Equal(`metadata.annotations["example.com/key"]`, path.String())
+	assert.Equal("spec.ports[0].port", fieldPath{"spec", "ports", 0, "port"}.String())
+
+	// string form of a path parses back into a pattern that matches it
+	for _, path := range []fieldPath{path, {"spec", "ports", 0, "port"}, {"data", "a[0]"}} {
+		pattern, err := parseFieldPathPattern(path.String())
+		if assert.NoError(err, path.String()) {
+			assert.True(pattern.matches(path), path.String())
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package util
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ContainerPort = "container"
+	ServicePort   = "service"
+)
+
+// PortInfo describes a port that a workload or a Service exposes
+type PortInfo struct {
+	// Kind and Name refer to the object that exposes the port
+	Kind      string
+	Name      string
+	Namespace string
+	Port      int32
+	Protocol  corev1.Protocol
+	// Type is either ContainerPort or ServicePort
+	Type string
+}
+
+func newPortInfo(object runtime.Object, port int32, protocol corev1.Protocol, portType string) PortInfo {
+	info := PortInfo{
+		Kind:     object.GetObjectKind().GroupVersionKind().Kind,
+		Port:     port,
+		Protocol: protocol,
+		Type:     portType,
+	}
+	if info.Protocol == "" {
+		info.Protocol = corev1.ProtocolTCP
+	}
+	if objectMeta, err := meta.Accessor(object); err == nil {
+		info.Name = objectMeta.GetName()
+		info.Namespace = objectMeta.GetNamespace()
+	}
+	return info
+}
+
+// PortsIn returns all ports that containers of workloads and Services in the list expose,
+// in the order objects appear in the list; protocol defaults to TCP when it's not set
+func PortsIn(list *metav1.List) []PortInfo {
+	ports := []PortInfo{}
+
+	rangeOverObjects(list, func(object runtime.Object) {
+		if service, ok := object.(*corev1.Service); ok {
+			for _, port := range service.Spec.Ports {
+				ports = append(ports, newPortInfo(object, port.Port, port.Protocol, ServicePort))
+			}
+			return
+		}
+		if _, podSpec, ok := podOf(object); ok {
+			rangeOverContainers(podSpec, func(container *corev1.Container) {
+				for _, port := range container.Ports {
+					ports = append(ports, newPortInfo(object, port.ContainerPort, port.Protocol, ContainerPort))
+				}
+			})
+		}
+	})
+
+	return ports
+}
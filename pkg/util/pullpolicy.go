@@ -0,0 +1,67 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// isLatestImage checks whether image refers to the latest tag, either
+// explicitly or by omission; images pinned by digest are never latest
+func isLatestImage(image string) bool {
+	if strings.Contains(image, "@") {
+		return false
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	i := strings.LastIndex(name, ":")
+	return i < 0 || name[i+1:] == "latest"
+}
+
+// expectedPullPolicy is Always for the latest images and IfNotPresent for
+// anything that is tagged, which is also what the API server defaults to
+func expectedPullPolicy(image string) corev1.PullPolicy {
+	if isLatestImage(image) {
+		return corev1.PullAlways
+	}
+	return corev1.PullIfNotPresent
+}
+
+// CheckPullPolicies warns about every container that has an imagePullPolicy that doesn't
+// fit its image, i.e. IfNotPresent with a latest image, or Always with a tagged one;
+// containers without a policy or with Never are assumed to be what the user intended
+func CheckPullPolicies(list *metav1.List) []Warning {
+	warnings := []Warning{}
+
+	rangeOverPods(list, func(workload runtime.Object, _ *metav1.ObjectMeta, podSpec *corev1.PodSpec) {
+		rangeOverContainers(podSpec, func(container *corev1.Container) {
+			policy := container.ImagePullPolicy
+			if policy == "" || policy == corev1.PullNever {
+				return
+			}
+			if expected := expectedPullPolicy(container.Image); policy != expected {
+				warnings = append(warnings, Warning{
+					Object:  describeObject(workload),
+					Message: fmt.Sprintf("container %q has image %q with imagePullPolicy %s, expected %s", container.Name, container.Image, policy, expected),
+				})
+			}
+		})
+	})
+
+	return warnings
+}
+
+// NormalizePullPolicies sets imagePullPolicy of every container to what CheckPullPolicies
+// expects, containers with Never are left untouched
+func NormalizePullPolicies(list *metav1.List) {
+	rangeOverPods(list, func(_ runtime.Object, _ *metav1.ObjectMeta, podSpec *corev1.PodSpec) {
+		rangeOverContainers(podSpec, func(container *corev1.Container) {
+			if container.ImagePullPolicy != corev1.PullNever {
+				container.ImagePullPolicy = expectedPullPolicy(container.Image)
+			}
+		})
+	})
+}
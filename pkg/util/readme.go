@@ -0,0 +1,71 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ReadmeFileName is the name of the file written when DumpOptions.Readme is set
+const ReadmeFileName = "README.md"
+
+// DefaultReadmeTemplate is used when DumpOptions.ReadmeTemplate is empty
+const DefaultReadmeTemplate = `# Generated resources
+
+| File | Kind | Name | Namespace | Images |
+|------|------|------|-----------|--------|
+{{- range . }}
+| [{{ .Path }}]({{ .Path }}) | {{ .Kind }} | {{ .Name }} | {{ .Namespace }} | {{ join .Images ", " }} |
+{{- end }}
+`
+
+// ReadmeEntry describes one of the files, it's what the README template ranges over
+type ReadmeEntry struct {
+	// Path is relative to the output directory
+	Path      string
+	Kind      string
+	Name      string
+	Namespace string
+	// Images are run by containers of a workload, in the order of containers
+	Images []string
+}
+
+func newReadmeEntry(object runtime.Object, relPath string) ReadmeEntry {
+	entry := ReadmeEntry{
+		Path: relPath,
+		Kind: object.GetObjectKind().GroupVersionKind().Kind,
+	}
+	if objectMeta, err := meta.Accessor(object); err == nil {
+		entry.Name = objectMeta.GetName()
+		entry.Namespace = objectMeta.GetNamespace()
+	}
+	if _, podSpec, ok := podOf(object); ok {
+		rangeOverContainers(podSpec, func(container *corev1.Container) {
+			entry.Images = append(entry.Images, container.Image)
+		})
+	}
+	return entry
+}
+
+func renderReadme(text string, entries []ReadmeEntry) ([]byte, error) {
+	if text == "" {
+		text = DefaultReadmeTemplate
+	}
+
+	tmpl, err := template.New(ReadmeFileName).Funcs(template.FuncMap{"join": strings.Join}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("kubegen/util: error parsing README template – %v", err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, entries); err != nil {
+		return nil, fmt.Errorf("kubegen/util: error rendering README template – %v", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,42 @@
+package util
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// nonGeneratableKinds are either read-only, or only exist as a request
+// to the API server, so it makes no sense to generate manifests for them
+var nonGeneratableKinds = map[string]bool{
+	"ComponentStatus":          true,
+	"Status":                   true,
+	"Binding":                  true,
+	"Eviction":                 true,
+	"Scale":                    true,
+	"TokenReview":              true,
+	"SubjectAccessReview":      true,
+	"LocalSubjectAccessReview": true,
+	"SelfSubjectAccessReview":  true,
+	"SelfSubjectRulesReview":   true,
+}
+
+func checkGeneratable(object runtime.Object) error {
+	if object == nil {
+		return nil
+	}
+	if list, ok := object.(*metav1.List); ok {
+		for _, item := range list.Items {
+			if err := checkGeneratable(item.Object); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if kind := object.GetObjectKind().GroupVersionKind().Kind; nonGeneratableKinds[kind] {
+		return fmt.Errorf("kubegen/util: kind %s is read-only and cannot be generated", kind)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package util
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	appsv1 "k8s.io/api/apps/v1"
+	appsv1beta1 "k8s.io/api/apps/v1beta1"
+	appsv1beta2 "k8s.io/api/apps/v1beta2"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2beta1 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	rbacv1beta1 "k8s.io/api/rbac/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RenameMapping records how AddNamePrefix or AddNameSuffix renamed an object
+type RenameMapping struct {
+	Kind      string
+	Namespace string
+	Old       string
+	New       string
+}
+
+// AddNamePrefix prepends prefix to names of all objects, see renameObjects
+func AddNamePrefix(list *metav1.List, prefix string) []RenameMapping {
+	return renameObjects(list, func(name string) string { return prefix + name })
+}
+
+// AddNameSuffix appends suffix to names of all objects, see renameObjects
+func AddNameSuffix(list *metav1.List, suffix string) []RenameMapping {
+	return renameObjects(list, func(name string) string { return name + suffix })
+}
+
+// renameObjects renames every object in the list, except for Namespaces, as other objects
+// refer to those by namespace; references that pods of workloads have to ConfigMaps,
+// Secrets, PersistentVolumeClaims and ServiceAccounts, serviceName of StatefulSets,
+// scaleTargetRef of HorizontalPodAutoscalers, backends and TLS secrets of Ingresses,
+// as well as roleRef and subjects of role bindings are updated if the objects they
+// refer to got renamed
+func renameObjects(list *metav1.List, rename func(string) string) []RenameMapping {
+	mappings := []RenameMapping{}
+	renamed := make(map[string]string)
+
+	ref := func(kind, namespace, name string) string {
+		return kind + "/" + namespace + "/" + name
+	}
+
+	rangeOverObjects(list, func(object runtime.Object) {
+		kind := object.GetObjectKind().GroupVersionKind().Kind
+		if kind == "Namespace" {
+			return
+		}
+		objectMeta, err := meta.Accessor(object)
+		if err != nil || objectMeta.GetName() == "" {
+			return
+		}
+
+		mapping := RenameMapping{
+			Kind:      kind,
+			Namespace: objectMeta.GetNamespace(),
+			Old:       objectMeta.GetName(),
+			New:       rename(objectMeta.GetName()),
+		}
+		objectMeta.SetName(mapping.New)
+		renamed[ref(mapping.Kind, mapping.Namespace, mapping.Old)] = mapping.New
+		mappings = append(mappings, mapping)
+	})
+
+	update := func(kind, namespace string, name *string) {
+		if newName, ok := renamed[ref(kind, namespace, *name)]; ok {
+			*name = newName
+		}
+	}
+
+	rangeOverPods(list, func(workload runtime.Object, _ *metav1.ObjectMeta, podSpec *corev1.PodSpec) {
+		namespace := namespaceOf(workload)
+
+		rangeOverPodReferences(podSpec, func(kind string, name *string, _ bool) {
+			update(kind, namespace, name)
+		})
+		for n := range podSpec.ImagePullSecrets {
+			update("Secret", namespace, &podSpec.ImagePullSecrets[n].Name)
+		}
+		for n := range podSpec.Volumes {
+			if claim := podSpec.Volumes[n].PersistentVolumeClaim; claim != nil {
+				update("PersistentVolumeClaim", namespace, &claim.ClaimName)
+			}
+		}
+		if podSpec.ServiceAccountName != "" {
+			update("ServiceAccount", namespace, &podSpec.ServiceAccountName)
+		}
+
+		switch o := workload.(type) {
+		case *appsv1.StatefulSet:
+			update("Service", namespace, &o.Spec.ServiceName)
+		case *appsv1beta2.StatefulSet:
+			update("Service", namespace, &o.Spec.ServiceName)
+		case *appsv1beta1.StatefulSet:
+			update("Service", namespace, &o.Spec.ServiceName)
+		}
+	})
+
+	rangeOverObjects(list, func(object runtime.Object) {
+		namespace := namespaceOf(object)
+
+		// roles that bindings refer to are namespaced the same as the binding,
+		// while cluster roles have no namespace
+		updateRoleRef := func(kind string, name *string) {
+			if kind == "ClusterRole" {
+				update(kind, "", name)
+			} else {
+				update(kind, namespace, name)
+			}
+		}
+		updateSubject := func(kind, subjectNamespace string, name *string) {
+			if kind != "ServiceAccount" {
+				return
+			}
+			if subjectNamespace == "" {
+				subjectNamespace = namespace
+			}
+			update(kind, subjectNamespace, name)
+		}
+
+		switch o := object.(type) {
+		case *autoscalingv1.HorizontalPodAutoscaler:
+			update(o.Spec.ScaleTargetRef.Kind, namespace, &o.Spec.ScaleTargetRef.Name)
+		case *autoscalingv2beta1.HorizontalPodAutoscaler:
+			update(o.Spec.ScaleTargetRef.Kind, namespace, &o.Spec.ScaleTargetRef.Name)
+		case *extensionsv1beta1.Ingress:
+			if o.Spec.Backend != nil {
+				update("Service", namespace, &o.Spec.Backend.ServiceName)
+			}
+			for n := range o.Spec.Rules {
+				if http := o.Spec.Rules[n].HTTP; http != nil {
+					for m := range http.Paths {
+						update("Service", namespace, &http.Paths[m].Backend.ServiceName)
+					}
+				}
+			}
+			for n := range o.Spec.TLS {
+				if o.Spec.TLS[n].SecretName != "" {
+					update("Secret", namespace, &o.Spec.TLS[n].SecretName)
+				}
+			}
+		case *rbacv1.RoleBinding:
+			updateRoleRef(o.RoleRef.Kind, &o.RoleRef.Name)
+			for n := range o.Subjects {
+				updateSubject(o.Subjects[n].Kind, o.Subjects[n].Namespace, &o.Subjects[n].Name)
+			}
+		case *rbacv1.ClusterRoleBinding:
+			updateRoleRef(o.RoleRef.Kind, &o.RoleRef.Name)
+			for n := range o.Subjects {
+				updateSubject(o.Subjects[n].Kind, o.Subjects[n].Namespace, &o.Subjects[n].Name)
+			}
+		case *rbacv1beta1.RoleBinding:
+			updateRoleRef(o.RoleRef.Kind, &o.RoleRef.Name)
+			for n := range o.Subjects {
+				updateSubject(o.Subjects[n].Kind, o.Subjects[n].Namespace, &o.Subjects[n].Name)
+			}
+		case *rbacv1beta1.ClusterRoleBinding:
+			updateRoleRef(o.RoleRef.Kind, &o.RoleRef.Name)
+			for n := range o.Subjects {
+				updateSubject(o.Subjects[n].Kind, o.Subjects[n].Namespace, &o.Subjects[n].Name)
+			}
+		}
+	})
+
+	return mappings
+}
@@ -0,0 +1,121 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAddNamePrefixReferences(t *testing.T) {
+	assert := assert.New(t)
+
+	objectMeta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: "prod"}
+	}
+	typeMeta := func(kind, apiVersion string) metav1.TypeMeta {
+		return metav1.TypeMeta{Kind: kind, APIVersion: apiVersion}
+	}
+
+	deployment := &appsv1.Deployment{
+		TypeMeta:   typeMeta("Deployment", "apps/v1"),
+		ObjectMeta: objectMeta("web"),
+	}
+	service := &corev1.Service{
+		TypeMeta:   typeMeta("Service", "v1"),
+		ObjectMeta: objectMeta("web"),
+	}
+	secret := &corev1.Secret{
+		TypeMeta:   typeMeta("Secret", "v1"),
+		ObjectMeta: objectMeta("tls"),
+	}
+	serviceAccount := &corev1.ServiceAccount{
+		TypeMeta:   typeMeta("ServiceAccount", "v1"),
+		ObjectMeta: objectMeta("web"),
+	}
+	role := &rbacv1.Role{
+		TypeMeta:   typeMeta("Role", "rbac.authorization.k8s.io/v1"),
+		ObjectMeta: objectMeta("reader"),
+	}
+	clusterRole := &rbacv1.ClusterRole{
+		TypeMeta:   typeMeta("ClusterRole", "rbac.authorization.k8s.io/v1"),
+		ObjectMeta: metav1.ObjectMeta{Name: "metrics-reader"},
+	}
+
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{
+		TypeMeta:   typeMeta("HorizontalPodAutoscaler", "autoscaling/v1"),
+		ObjectMeta: objectMeta("web"),
+		Spec: autoscalingv1.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{Kind: "Deployment", Name: "web", APIVersion: "apps/v1"},
+			MaxReplicas:    3,
+		},
+	}
+	ingress := &extensionsv1beta1.Ingress{
+		TypeMeta:   typeMeta("Ingress", "extensions/v1beta1"),
+		ObjectMeta: objectMeta("web"),
+		Spec: extensionsv1beta1.IngressSpec{
+			Backend: &extensionsv1beta1.IngressBackend{ServiceName: "web"},
+			TLS:     []extensionsv1beta1.IngressTLS{{SecretName: "tls"}},
+			Rules: []extensionsv1beta1.IngressRule{{
+				Host: "example.com",
+				IngressRuleValue: extensionsv1beta1.IngressRuleValue{
+					HTTP: &extensionsv1beta1.HTTPIngressRuleValue{
+						Paths: []extensionsv1beta1.HTTPIngressPath{
+							{Path: "/", Backend: extensionsv1beta1.IngressBackend{ServiceName: "web"}},
+							{Path: "/api", Backend: extensionsv1beta1.IngressBackend{ServiceName: "api"}},
+						},
+					},
+				},
+			}},
+		},
+	}
+	roleBinding := &rbacv1.RoleBinding{
+		TypeMeta:   typeMeta("RoleBinding", "rbac.authorization.k8s.io/v1"),
+		ObjectMeta: objectMeta("web-reader"),
+		RoleRef:    rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "Role", Name: "reader"},
+		Subjects: []rbacv1.Subject{
+			{Kind: "ServiceAccount", Name: "web"},
+			{Kind: "ServiceAccount", Name: "web", Namespace: "other"},
+			{Kind: "User", Name: "web"},
+		},
+	}
+	clusterRoleBinding := &rbacv1.ClusterRoleBinding{
+		TypeMeta:   typeMeta("ClusterRoleBinding", "rbac.authorization.k8s.io/v1"),
+		ObjectMeta: metav1.ObjectMeta{Name: "web-metrics-reader"},
+		RoleRef:    rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: "metrics-reader"},
+		Subjects:   []rbacv1.Subject{{Kind: "ServiceAccount", Name: "web", Namespace: "prod"}},
+	}
+
+	list := &metav1.List{}
+	for _, object := range []runtime.Object{
+		deployment, service, secret, serviceAccount, role, clusterRole,
+		hpa, ingress, roleBinding, clusterRoleBinding,
+	} {
+		list.Items = append(list.Items, runtime.RawExtension{Object: object})
+	}
+
+	AddNamePrefix(list, "staging-")
+
+	assert.Equal("staging-web", hpa.Spec.ScaleTargetRef.Name)
+
+	assert.Equal("staging-web", ingress.Spec.Backend.ServiceName)
+	assert.Equal("staging-web", ingress.Spec.Rules[0].HTTP.Paths[0].Backend.ServiceName)
+	assert.Equal("api", ingress.Spec.Rules[0].HTTP.Paths[1].Backend.ServiceName, "services that are not in the list must not be renamed")
+	assert.Equal("staging-tls", ingress.Spec.TLS[0].SecretName)
+
+	assert.Equal("staging-reader", roleBinding.RoleRef.Name)
+	assert.Equal("staging-web", roleBinding.Subjects[0].Name)
+	assert.Equal("web", roleBinding.Subjects[1].Name, "service accounts in other namespaces must not be renamed")
+	assert.Equal("web", roleBinding.Subjects[2].Name, "users must not be renamed")
+
+	assert.Equal("staging-metrics-reader", clusterRoleBinding.RoleRef.Name)
+	assert.Equal("staging-web", clusterRoleBinding.Subjects[0].Name)
+}
@@ -0,0 +1,47 @@
+package util
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// Scope tells whether objects of a kind belong to a namespace
+type Scope int
+
+const (
+	// Namespaced objects belong to a namespace, e.g. Deployments
+	Namespaced Scope = iota
+	// ClusterScoped objects don't belong to any namespace, e.g. ClusterRoles
+	ClusterScoped
+)
+
+// clusterScopedKinds are kinds of objects that don't belong to a namespace
+var clusterScopedKinds = map[string]bool{
+	"Namespace":                      true,
+	"Node":                           true,
+	"PersistentVolume":               true,
+	"StorageClass":                   true,
+	"ClusterRole":                    true,
+	"ClusterRoleBinding":             true,
+	"CustomResourceDefinition":       true,
+	"APIService":                     true,
+	"PodSecurityPolicy":              true,
+	"PriorityClass":                  true,
+	"CertificateSigningRequest":      true,
+	"ComponentStatus":                true,
+	"InitializerConfiguration":       true,
+	"MutatingWebhookConfiguration":   true,
+	"ValidatingWebhookConfiguration": true,
+}
+
+// isNamespacedKind checks whether objects of the kind are known to belong to a namespace,
+// scope of kinds that are neither built-in nor registered with RegisterTypes is unknown
+func isNamespacedKind(gvk schema.GroupVersionKind) bool {
+	if scope, ok := customKindScope(gvk); ok {
+		return scope == Namespaced
+	}
+	if gvk.Kind == "" || gvk.Kind == "List" || clusterScopedKinds[gvk.Kind] {
+		return false
+	}
+	return scheme.Scheme.Recognizes(gvk)
+}
@@ -0,0 +1,95 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type scopeTestWidget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+}
+
+func (w *scopeTestWidget) DeepCopyObject() runtime.Object {
+	c := *w
+	w.ObjectMeta.DeepCopyInto(&c.ObjectMeta)
+	return &c
+}
+
+type scopeTestGadget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+}
+
+func (g *scopeTestGadget) DeepCopyObject() runtime.Object {
+	c := *g
+	g.ObjectMeta.DeepCopyInto(&c.ObjectMeta)
+	return &c
+}
+
+func TestIsNamespacedKind(t *testing.T) {
+	assert := assert.New(t)
+
+	gv := schema.GroupVersion{Group: "scope.kubegen.test", Version: "v1"}
+	widget, gadget := gv.WithKind("scopeTestWidget"), gv.WithKind("scopeTestGadget")
+
+	assert.True(isNamespacedKind(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}))
+	assert.True(isNamespacedKind(schema.GroupVersionKind{Version: "v1", Kind: "Service"}))
+	assert.False(isNamespacedKind(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}))
+	assert.False(isNamespacedKind(schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"}))
+	assert.False(isNamespacedKind(schema.GroupVersionKind{Version: "v1", Kind: "List"}))
+	assert.False(isNamespacedKind(schema.GroupVersionKind{}))
+
+	// scope of unregistered custom resources is unknown
+	assert.False(isNamespacedKind(widget))
+	assert.False(isNamespacedKind(gadget))
+
+	if !assert.NoError(RegisterTypes(gv, Namespaced, &scopeTestWidget{})) {
+		return
+	}
+	if !assert.NoError(RegisterTypes(gv, ClusterScoped, &scopeTestGadget{})) {
+		return
+	}
+	assert.True(isNamespacedKind(widget))
+	assert.False(isNamespacedKind(gadget))
+}
+
+func TestDefaultNamespace(t *testing.T) {
+	assert := assert.New(t)
+
+	opts := EncodeOptions{DefaultNamespace: "prod"}
+
+	service := &corev1.Service{
+		TypeMeta:   metav1.TypeMeta{Kind: "Service", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+	}
+	data, err := EncodeWithOptions(service, "application/yaml", opts)
+	if assert.NoError(err) {
+		assert.Contains(string(data), "namespace: prod")
+	}
+
+	for _, object := range []runtime.Object{
+		&corev1.Namespace{
+			TypeMeta:   metav1.TypeMeta{Kind: "Namespace", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "prod"},
+		},
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "example.com/v1",
+			"kind":       "ClusterIssuer",
+			"metadata":   map[string]interface{}{"name": "letsencrypt"},
+		}},
+	} {
+		data, err := EncodeWithOptions(object, "application/yaml", opts)
+		if assert.NoError(err) {
+			assert.NotContains(string(data), "namespace:", string(data))
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package util
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CheckSecurityContext warns about every container that runs privileged, as root (i.e.
+// runAsUser is 0, as set on the container or inherited from the pod), with privilege
+// escalation explicitly allowed, or with added capabilities; containers that don't set
+// runAsUser at all are not reported, as the user is then determined by the image
+func CheckSecurityContext(list *metav1.List) []Warning {
+	warnings := []Warning{}
+
+	rangeOverPods(list, func(workload runtime.Object, _ *metav1.ObjectMeta, podSpec *corev1.PodSpec) {
+		warn := func(format string, args ...interface{}) {
+			warnings = append(warnings, Warning{
+				Object:  describeObject(workload),
+				Message: fmt.Sprintf(format, args...),
+			})
+		}
+
+		var podRunAsUser *int64
+		if podSpec.SecurityContext != nil {
+			podRunAsUser = podSpec.SecurityContext.RunAsUser
+		}
+
+		rangeOverContainers(podSpec, func(container *corev1.Container) {
+			runAsUser := podRunAsUser
+			securityContext := container.SecurityContext
+			if securityContext != nil && securityContext.RunAsUser != nil {
+				runAsUser = securityContext.RunAsUser
+			}
+			if runAsUser != nil && *runAsUser == 0 {
+				warn("container %q runs as root", container.Name)
+			}
+
+			if securityContext == nil {
+				return
+			}
+			if securityContext.Privileged != nil && *securityContext.Privileged {
+				warn("container %q runs privileged", container.Name)
+			}
+			if securityContext.AllowPrivilegeEscalation != nil && *securityContext.AllowPrivilegeEscalation {
+				warn("container %q allows privilege escalation", container.Name)
+			}
+			if capabilities := securityContext.Capabilities; capabilities != nil {
+				for _, capability := range capabilities.Add {
+					warn("container %q adds capability %s", container.Name, capability)
+				}
+			}
+		})
+	})
+
+	return warnings
+}
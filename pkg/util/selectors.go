@@ -0,0 +1,52 @@
+package util
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SelectorInfo describes a label selector of a Service or a workload
+type SelectorInfo struct {
+	// Kind and Name refer to the object that has the selector
+	Kind      string
+	Name      string
+	Namespace string
+	Selector  map[string]string
+	// MatchExpressions are only ever set for workloads
+	MatchExpressions []metav1.LabelSelectorRequirement
+}
+
+// SelectorsIn returns selectors of all Services and workloads in the list,
+// in the order objects appear in the list
+func SelectorsIn(list *metav1.List) []SelectorInfo {
+	selectors := []SelectorInfo{}
+
+	rangeOverObjects(list, func(object runtime.Object) {
+		info := SelectorInfo{
+			Kind: object.GetObjectKind().GroupVersionKind().Kind,
+		}
+		if objectMeta, err := meta.Accessor(object); err == nil {
+			info.Name = objectMeta.GetName()
+			info.Namespace = objectMeta.GetNamespace()
+		}
+
+		if service, ok := object.(*corev1.Service); ok {
+			if len(service.Spec.Selector) == 0 {
+				return
+			}
+			info.Selector = service.Spec.Selector
+		} else if selector, ok := selectorOf(object); ok {
+			info.Selector = selector.MatchLabels
+			info.MatchExpressions = selector.MatchExpressions
+		} else {
+			return
+		}
+
+		selectors = append(selectors, info)
+	})
+
+	return selectors
+}
@@ -0,0 +1,126 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DecodeAll decodes every document in a stream of YAML documents
+// (separated by "---") or concatenated JSON objects into a list
+func DecodeAll(data []byte) (*metav1.List, error) {
+	return DecodeAllFrom(bytes.NewReader(data))
+}
+
+// DecodeAllFrom is like DecodeAll, but reads documents from r as it goes,
+// gzipped input gets decompressed on the fly
+func DecodeAllFrom(r io.Reader) (*metav1.List, error) {
+	r, err := NewDecompressingReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &metav1.List{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "List",
+			APIVersion: "v1",
+		},
+	}
+
+	decoder := utilyaml.NewYAMLOrJSONDecoder(r, 4096)
+	for n := 0; ; n++ {
+		raw := runtime.RawExtension{}
+		if err := decoder.Decode(&raw); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("kubegen/util: error decoding document #%d – %v", n, err)
+		}
+
+		raw.Raw = bytes.TrimSpace(raw.Raw)
+		if len(raw.Raw) == 0 || bytes.Equal(raw.Raw, []byte("null")) {
+			continue
+		}
+
+		obj, err := Decode(raw.Raw)
+		if err != nil {
+			return nil, fmt.Errorf("kubegen/util: error decoding document #%d – %v", n, err)
+		}
+		if err := appendFlattened(list, obj); err != nil {
+			return nil, fmt.Errorf("kubegen/util: error decoding document #%d – %v", n, err)
+		}
+	}
+
+	return list, nil
+}
+
+// appendFlattened appends obj to the list, or each of its items if obj is a list,
+// e.g. what `kubectl get -o yaml` outputs; items get decoded to typed objects
+func appendFlattened(list *metav1.List, obj runtime.Object) error {
+	var items []runtime.RawExtension
+	switch o := obj.(type) {
+	case *corev1.List:
+		items = o.Items
+	case *metav1.List:
+		items = o.Items
+	default:
+		list.Items = append(list.Items, runtime.RawExtension{Object: obj})
+		return nil
+	}
+
+	for n, item := range items {
+		itemObj := item.Object
+		if itemObj == nil {
+			var err error
+			if itemObj, err = Decode(item.Raw); err != nil {
+				return fmt.Errorf("error decoding item #%d – %v", n, err)
+			}
+		}
+		if err := appendFlattened(list, itemObj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecodeList decodes an object of kind List, such as the output of `kubectl get -o yaml`,
+// any other kind of object is returned as a list with just that one item
+func DecodeList(data []byte) (*metav1.List, error) {
+	obj, err := Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &metav1.List{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "List",
+			APIVersion: "v1",
+		},
+	}
+	if err := appendFlattened(list, obj); err != nil {
+		return nil, fmt.Errorf("kubegen/util: error decoding list – %v", err)
+	}
+	return list, nil
+}
+
+// SplitFile decodes a multi-document file and writes each of the
+// objects into dir, using the same filenames that DumpListToFiles uses
+func SplitFile(data []byte, contentType string, dir string) ([]string, error) {
+	list, err := DecodeAll(data)
+	if err != nil {
+		return nil, err
+	}
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("kubegen/util: error creating output directory %q – %v", dir, err)
+		}
+	}
+	return DumpListToFilesWithOptions(list, contentType, DumpOptions{Dir: dir})
+}
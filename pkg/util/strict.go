@@ -0,0 +1,88 @@
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	yamlv2 "gopkg.in/yaml.v2"
+)
+
+// CheckDuplicateKeys returns an error naming the first key that appears more than
+// once in the same mapping of any of the YAML (or JSON) documents in data, as the
+// decoders silently keep only the last value for such key; gzipped data is
+// decompressed first, and documents that cannot be parsed are reported as well
+func CheckDuplicateKeys(data []byte) error {
+	data, _, err := maybeGunzip(data, "")
+	if err != nil {
+		return err
+	}
+
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+	for n := 0; ; n++ {
+		document, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("kubegen/util: error reading document #%d – %v", n, err)
+		}
+
+		doc := yamlv2.MapSlice{}
+		if err := yamlv2.Unmarshal(document, &doc); err != nil {
+			return fmt.Errorf("kubegen/util: error parsing document #%d – %v", n, err)
+		}
+		if err := checkDuplicateKeys(doc, fieldPath{}); err != nil {
+			return err
+		}
+	}
+}
+
+func checkDuplicateKeys(value interface{}, at fieldPath) error {
+	switch value := value.(type) {
+	case yamlv2.MapSlice:
+		seen := make(map[string]bool, len(value))
+		for _, item := range value {
+			key := fmt.Sprint(item.Key)
+			if seen[key] {
+				return fmt.Errorf("kubegen/util: duplicate key %q found at %q", key, at.key(key).String())
+			}
+			seen[key] = true
+			if err := checkDuplicateKeys(item.Value, at.key(key)); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for n, x := range value {
+			if err := checkDuplicateKeys(x, at.index(n)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// DecodeStrict is like Decode, but fails on duplicate keys
+func DecodeStrict(data []byte) (runtime.Object, error) {
+	if err := CheckDuplicateKeys(data); err != nil {
+		return nil, err
+	}
+	return Decode(data)
+}
+
+// LoadObjStrict is like LoadObj, but fails on duplicate keys in YAML and JSON manifests
+func LoadObjStrict(obj interface{}, data []byte, sourcePath string, instanceName string) error {
+	switch path.Ext(strings.TrimSuffix(sourcePath, ".gz")) {
+	case ".json", ".yaml", ".yml":
+		if err := CheckDuplicateKeys(data); err != nil {
+			return fmt.Errorf("%v (%q)", err, sourcePath)
+		}
+	}
+	return LoadObj(obj, data, sourcePath, instanceName)
+}
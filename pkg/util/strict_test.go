@@ -0,0 +1,65 @@
+package util
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func gzipForStrictTest(t *testing.T, data string) []byte {
+	buf := &bytes.Buffer{}
+	w := gzip.NewWriter(buf)
+	if _, err := w.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCheckDuplicateKeys(t *testing.T) {
+	const (
+		unique    = "kind: ConfigMap\nmetadata:\n  name: a\n"
+		duplicate = "kind: ConfigMap\nmetadata:\n  name: a\n  name: b\n"
+	)
+
+	tests := []struct {
+		name string
+		data []byte
+		err  bool
+	}{
+		{name: "unique keys", data: []byte(unique)},
+		{name: "duplicate keys", data: []byte(duplicate), err: true},
+		{name: "duplicate JSON keys", data: []byte(`{"kind": "ConfigMap", "kind": "Secret"}`), err: true},
+		{name: "gzipped unique keys", data: gzipForStrictTest(t, unique)},
+		{name: "gzipped duplicate keys", data: gzipForStrictTest(t, duplicate), err: true},
+		{name: "duplicate keys in second document", data: []byte(unique + "---\n" + duplicate), err: true},
+		{name: "empty documents", data: []byte("---\n" + unique + "---\n---\n" + unique)},
+		{name: "invalid document", data: []byte(unique + "---\nkind: [ConfigMap\n"), err: true},
+	}
+
+	for _, test := range tests {
+		err := CheckDuplicateKeys(test.data)
+		if test.err {
+			assert.Error(t, err, test.name)
+		} else {
+			assert.NoError(t, err, test.name)
+		}
+	}
+}
+
+func TestLoadObjStrict(t *testing.T) {
+	assert := assert.New(t)
+
+	duplicate := gzipForStrictTest(t, "kind: ConfigMap\nkind: Secret\n")
+	obj := map[string]interface{}{}
+	assert.Error(LoadObjStrict(&obj, duplicate, "manifest.yaml.gz", ""))
+
+	unique := gzipForStrictTest(t, "kind: ConfigMap\n")
+	if assert.NoError(LoadObjStrict(&obj, unique, "manifest.yaml.gz", "")) {
+		assert.Equal("ConfigMap", obj["kind"])
+	}
+}
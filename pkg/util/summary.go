@@ -0,0 +1,163 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Summary is an overview of objects in a list
+type Summary struct {
+	// Objects is the total number of objects
+	Objects int
+	// Kinds is the number of objects of each kind
+	Kinds map[string]int
+	// Containers is the total number of containers (including init containers)
+	Containers int
+	// Images are all container images, sorted and deduplicated
+	Images []string
+	// Namespaces are all namespaces objects belong to, sorted and deduplicated
+	Namespaces []string
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := []string{}
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// NewSummary computes the summary of a list
+func NewSummary(list *metav1.List) *Summary {
+	summary := &Summary{Kinds: make(map[string]int)}
+	images := make(map[string]bool)
+	namespaces := make(map[string]bool)
+
+	rangeOverObjects(list, func(object runtime.Object) {
+		summary.Objects++
+		summary.Kinds[object.GetObjectKind().GroupVersionKind().Kind]++
+		if namespace := namespaceOf(object); namespace != "" {
+			namespaces[namespace] = true
+		}
+		if _, podSpec, ok := podOf(object); ok {
+			rangeOverContainers(podSpec, func(container *corev1.Container) {
+				summary.Containers++
+				images[container.Image] = true
+			})
+		}
+	})
+
+	summary.Images = sortedKeys(images)
+	summary.Namespaces = sortedKeys(namespaces)
+
+	return summary
+}
+
+func (s *Summary) String() string {
+	buf := &bytes.Buffer{}
+
+	fmt.Fprintf(buf, "Objects: %d\n", s.Objects)
+	kinds := []string{}
+	for kind := range s.Kinds {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		fmt.Fprintf(buf, "  %s: %d\n", kind, s.Kinds[kind])
+	}
+
+	fmt.Fprintf(buf, "Containers: %d\n", s.Containers)
+	if len(s.Images) > 0 {
+		fmt.Fprintf(buf, "Images:\n")
+		for _, image := range s.Images {
+			fmt.Fprintf(buf, "  – %s\n", image)
+		}
+	}
+	if len(s.Namespaces) > 0 {
+		fmt.Fprintf(buf, "Namespaces:\n")
+		for _, namespace := range s.Namespaces {
+			fmt.Fprintf(buf, "  – %s\n", namespace)
+		}
+	}
+
+	return buf.String()
+}
+
+// Summarize returns a human-readable overview of objects in a list
+func Summarize(list *metav1.List) string {
+	return NewSummary(list).String()
+}
+
+// Describe returns a one-line description of an object, for example
+// "web (Deployment, apps/v1): 3 replicas, image nginx:1.13, exposes 80"
+func Describe(object runtime.Object) string {
+	gvk := object.GetObjectKind().GroupVersionKind()
+
+	name := ""
+	if objectMeta, err := meta.Accessor(object); err == nil {
+		name = objectMeta.GetName()
+		if namespace := objectMeta.GetNamespace(); namespace != "" {
+			name = namespace + "/" + name
+		}
+	}
+
+	details := []string{}
+
+	if replicas, ok := replicasFieldOf(object); ok {
+		n := int32(1)
+		if *replicas != nil {
+			n = **replicas
+		}
+		if n == 1 {
+			details = append(details, "1 replica")
+		} else {
+			details = append(details, fmt.Sprintf("%d replicas", n))
+		}
+	}
+
+	ports := []string{}
+	if _, podSpec, ok := podOf(object); ok {
+		images := []string{}
+		rangeOverContainers(podSpec, func(container *corev1.Container) {
+			images = append(images, container.Image)
+			for _, port := range container.Ports {
+				ports = append(ports, fmt.Sprintf("%d", port.ContainerPort))
+			}
+		})
+		switch len(images) {
+		case 0:
+		case 1:
+			details = append(details, "image "+images[0])
+		default:
+			details = append(details, "images "+strings.Join(images, ", "))
+		}
+	}
+
+	if service, ok := object.(*corev1.Service); ok {
+		if service.Spec.Type != "" {
+			details = append(details, string(service.Spec.Type))
+		}
+		for _, port := range service.Spec.Ports {
+			ports = append(ports, fmt.Sprintf("%d", port.Port))
+		}
+	}
+
+	if len(ports) != 0 {
+		details = append(details, "exposes "+strings.Join(ports, ", "))
+	}
+
+	description := fmt.Sprintf("%s (%s, %s)", name, gvk.Kind, gvk.GroupVersion().String())
+	if len(details) == 0 {
+		return description
+	}
+	return description + ": " + strings.Join(details, ", ")
+}
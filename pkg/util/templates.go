@@ -0,0 +1,29 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	"github.com/ghodss/yaml"
+)
+
+// NewFromTemplatedYAML renders data as a Go template with the given values, and decodes
+// the resulting YAML into obj; referencing a value that is not set results in an error
+func NewFromTemplatedYAML(obj interface{}, data []byte, values map[string]interface{}) error {
+	tmpl, err := template.New("manifest").Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return fmt.Errorf("kubegen/util: error parsing YAML template – %v", err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, values); err != nil {
+		return fmt.Errorf("kubegen/util: error rendering YAML template – %v", err)
+	}
+
+	if err := yaml.Unmarshal(buf.Bytes(), obj); err != nil {
+		return fmt.Errorf("kubegen/util: error constructing an object from rendered YAML template – %v", err)
+	}
+
+	return nil
+}
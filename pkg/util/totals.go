@@ -0,0 +1,62 @@
+package util
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// podResources returns what a single pod needs, that is the sum of all its containers, or
+// what the largest init container needs if that's more, as init containers run one by one
+func podResources(podSpec *corev1.PodSpec, get func(corev1.ResourceRequirements) corev1.ResourceList) corev1.ResourceList {
+	total := corev1.ResourceList{}
+	for _, container := range podSpec.Containers {
+		for name, quantity := range get(container.Resources) {
+			sum := total[name]
+			sum.Add(quantity)
+			total[name] = sum
+		}
+	}
+	for _, container := range podSpec.InitContainers {
+		for name, quantity := range get(container.Resources) {
+			if current, ok := total[name]; !ok || quantity.Cmp(current) > 0 {
+				total[name] = quantity.DeepCopy()
+			}
+		}
+	}
+	return total
+}
+
+func addResources(total, pod corev1.ResourceList, replicas int64) {
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		quantity, ok := pod[name]
+		if !ok {
+			continue
+		}
+		// CPU is commonly specified in millicores, memory is always a whole number of bytes
+		scaled := resource.NewQuantity(quantity.Value()*replicas, quantity.Format)
+		if name == corev1.ResourceCPU {
+			scaled = resource.NewMilliQuantity(quantity.MilliValue()*replicas, quantity.Format)
+		}
+		if sum, ok := total[name]; ok {
+			scaled.Add(sum)
+		}
+		total[name] = *scaled
+	}
+}
+
+// ResourceTotals adds up CPU and memory requests and limits of pods that all of the workloads
+// in the list run, taking replicas into account; a DaemonSet is counted as one pod
+func ResourceTotals(list *metav1.List) (requests, limits corev1.ResourceList) {
+	requests, limits = corev1.ResourceList{}, corev1.ResourceList{}
+
+	rangeOverPods(list, func(workload runtime.Object, _ *metav1.ObjectMeta, podSpec *corev1.PodSpec) {
+		replicas := replicasOf(workload)
+		addResources(requests, podResources(podSpec, func(r corev1.ResourceRequirements) corev1.ResourceList { return r.Requests }), replicas)
+		addResources(limits, podResources(podSpec, func(r corev1.ResourceRequirements) corev1.ResourceList { return r.Limits }), replicas)
+	})
+
+	return requests, limits
+}
@@ -0,0 +1,301 @@
+package util
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ApplyAntiAffinity makes pods of every workload in the list prefer to be
+// scheduled away from each other across the given topology domain (e.g.
+// "kubernetes.io/hostname"), pods are matched by the values of their own
+// labels with labelSelectorKeys, or by all their labels if no keys are given
+func ApplyAntiAffinity(list *metav1.List, topologyKey string, labelSelectorKeys []string) {
+	rangeOverPods(list, func(_ runtime.Object, podMeta *metav1.ObjectMeta, podSpec *corev1.PodSpec) {
+		matchLabels := make(map[string]string)
+		if len(labelSelectorKeys) == 0 {
+			for k, v := range podMeta.Labels {
+				matchLabels[k] = v
+			}
+		} else {
+			for _, k := range labelSelectorKeys {
+				if v, ok := podMeta.Labels[k]; ok {
+					matchLabels[k] = v
+				}
+			}
+		}
+		if len(matchLabels) == 0 {
+			return
+		}
+
+		term := corev1.WeightedPodAffinityTerm{
+			Weight: 100,
+			PodAffinityTerm: corev1.PodAffinityTerm{
+				LabelSelector: &metav1.LabelSelector{MatchLabels: matchLabels},
+				TopologyKey:   topologyKey,
+			},
+		}
+
+		if podSpec.Affinity == nil {
+			podSpec.Affinity = &corev1.Affinity{}
+		}
+		if podSpec.Affinity.PodAntiAffinity == nil {
+			podSpec.Affinity.PodAntiAffinity = &corev1.PodAntiAffinity{}
+		}
+		antiAffinity := podSpec.Affinity.PodAntiAffinity
+
+		for _, existing := range antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+			if reflect.DeepEqual(existing.PodAffinityTerm, term.PodAffinityTerm) {
+				return
+			}
+		}
+		antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution, term)
+	})
+}
+
+func isHTTPPort(port corev1.ContainerPort) bool {
+	if port.Protocol != "" && port.Protocol != corev1.ProtocolTCP {
+		return false
+	}
+	switch {
+	case port.Name == "http" || port.Name == "web" || strings.HasPrefix(port.Name, "http-"):
+		return true
+	case port.Name == "" && (port.ContainerPort == 80 || port.ContainerPort == 8080):
+		return true
+	}
+	return false
+}
+
+// ApplyDefaultProbes adds HTTP readiness and liveness probes to every container that
+// exposes a single port that looks like an HTTP port (named "http", "http-*" or "web",
+// or an unnamed port 80 or 8080) and doesn't have any probes defined yet
+func ApplyDefaultProbes(list *metav1.List) {
+	rangeOverPods(list, func(_ runtime.Object, _ *metav1.ObjectMeta, podSpec *corev1.PodSpec) {
+		for n := range podSpec.Containers {
+			container := &podSpec.Containers[n]
+			if container.ReadinessProbe != nil || container.LivenessProbe != nil {
+				continue
+			}
+			if len(container.Ports) != 1 || !isHTTPPort(container.Ports[0]) {
+				continue
+			}
+
+			port := intstr.FromInt(int(container.Ports[0].ContainerPort))
+			if name := container.Ports[0].Name; name != "" {
+				port = intstr.FromString(name)
+			}
+
+			handler := func() corev1.Handler {
+				return corev1.Handler{
+					HTTPGet: &corev1.HTTPGetAction{Path: "/", Port: port},
+				}
+			}
+			container.ReadinessProbe = &corev1.Probe{
+				Handler:             handler(),
+				InitialDelaySeconds: 5,
+				PeriodSeconds:       10,
+			}
+			container.LivenessProbe = &corev1.Probe{
+				Handler:             handler(),
+				InitialDelaySeconds: 15,
+				PeriodSeconds:       20,
+			}
+		}
+	})
+}
+
+// SetPriorityClass sets priorityClassName of pods of every workload that doesn't have one yet
+func SetPriorityClass(list *metav1.List, className string) {
+	rangeOverPods(list, func(_ runtime.Object, _ *metav1.ObjectMeta, podSpec *corev1.PodSpec) {
+		if podSpec.PriorityClassName == "" {
+			podSpec.PriorityClassName = className
+		}
+	})
+}
+
+// DedupeEnv removes env vars that are overridden by a var with the same name
+// defined later in the same container, as only the last definition takes effect
+func DedupeEnv(list *metav1.List) {
+	rangeOverPods(list, func(_ runtime.Object, _ *metav1.ObjectMeta, podSpec *corev1.PodSpec) {
+		rangeOverContainers(podSpec, func(container *corev1.Container) {
+			last := make(map[string]int, len(container.Env))
+			for n, env := range container.Env {
+				last[env.Name] = n
+			}
+			if len(last) == len(container.Env) {
+				return
+			}
+			env := []corev1.EnvVar{}
+			for n, v := range container.Env {
+				if last[v.Name] == n {
+					env = append(env, v)
+				}
+			}
+			container.Env = env
+		})
+	})
+}
+
+// RewriteImages replaces image of every init container and container with what
+// rewrite returns for it, e.g. to pull all images from a mirror registry
+func RewriteImages(list *metav1.List, rewrite func(image string) string) {
+	rangeOverPods(list, func(_ runtime.Object, _ *metav1.ObjectMeta, podSpec *corev1.PodSpec) {
+		rangeOverContainers(podSpec, func(container *corev1.Container) {
+			container.Image = rewrite(container.Image)
+		})
+	})
+}
+
+// TransformFunc modifies an object in place
+type TransformFunc func(object runtime.Object) error
+
+// Transform applies each of fns to every object in the list, in the order given,
+// it stops at the first error
+func Transform(list *metav1.List, fns ...TransformFunc) error {
+	var err error
+	rangeOverObjects(list, func(object runtime.Object) {
+		if err != nil {
+			return
+		}
+		for _, fn := range fns {
+			if err = fn(object); err != nil {
+				err = fmt.Errorf("kubegen/util: error transforming %s – %v", describeObject(object), err)
+				return
+			}
+		}
+	})
+	return err
+}
+
+// ClearNamespaces removes namespace from every object, so that the
+// namespace can be chosen when the objects get applied
+func ClearNamespaces(list *metav1.List) {
+	rangeOverObjects(list, func(object runtime.Object) {
+		if objectMeta, err := meta.Accessor(object); err == nil {
+			objectMeta.SetNamespace("")
+		}
+	})
+}
+
+// SetContainerImage sets image of every init container and container with the
+// given name in all workloads, it returns how many containers got updated
+func SetContainerImage(list *metav1.List, containerName, image string) int {
+	updated := 0
+	rangeOverPods(list, func(_ runtime.Object, _ *metav1.ObjectMeta, podSpec *corev1.PodSpec) {
+		rangeOverContainers(podSpec, func(container *corev1.Container) {
+			if container.Name == containerName && container.Image != image {
+				container.Image = image
+				updated++
+			}
+		})
+	})
+	return updated
+}
+
+// ApplyNodePlacement adds nodeSelector and tolerations to pods of every workload,
+// labels already present in a nodeSelector of a workload are kept as they are, and
+// tolerations that a workload already has are not duplicated
+func ApplyNodePlacement(list *metav1.List, nodeSelector map[string]string, tolerations []corev1.Toleration) {
+	rangeOverPods(list, func(_ runtime.Object, _ *metav1.ObjectMeta, podSpec *corev1.PodSpec) {
+		for key, value := range nodeSelector {
+			if podSpec.NodeSelector == nil {
+				podSpec.NodeSelector = make(map[string]string, len(nodeSelector))
+			}
+			if _, ok := podSpec.NodeSelector[key]; !ok {
+				podSpec.NodeSelector[key] = value
+			}
+		}
+
+		for _, toleration := range tolerations {
+			exists := false
+			for _, existing := range podSpec.Tolerations {
+				if reflect.DeepEqual(existing, toleration) {
+					exists = true
+					break
+				}
+			}
+			if !exists {
+				podSpec.Tolerations = append(podSpec.Tolerations, toleration)
+			}
+		}
+	})
+}
+
+func setAnnotation(objectMeta metav1.Object, key, value string) {
+	annotations := objectMeta.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string, 1)
+	}
+	annotations[key] = value
+	objectMeta.SetAnnotations(annotations)
+}
+
+// SetRevisionAnnotation records revision (e.g. a git commit) in the given annotation of every object
+func SetRevisionAnnotation(list *metav1.List, key, revision string) {
+	rangeOverObjects(list, func(object runtime.Object) {
+		if objectMeta, err := meta.Accessor(object); err == nil {
+			setAnnotation(objectMeta, key, revision)
+		}
+	})
+}
+
+// SetPodTemplateRevisionAnnotation is like SetRevisionAnnotation, but annotates pod templates
+// of workloads instead, so that pods get replaced whenever the revision changes
+func SetPodTemplateRevisionAnnotation(list *metav1.List, key, revision string) {
+	rangeOverPods(list, func(_ runtime.Object, podMeta *metav1.ObjectMeta, _ *corev1.PodSpec) {
+		setAnnotation(podMeta, key, revision)
+	})
+}
+
+// SetReplicas sets replicas of every workload of any of the given kinds (e.g. "Deployment"),
+// or of every workload that has replicas when no kinds are given
+func SetReplicas(list *metav1.List, replicas int32, kinds ...string) {
+	matches := func(kind string) bool {
+		if len(kinds) == 0 {
+			return true
+		}
+		for _, k := range kinds {
+			if k == kind {
+				return true
+			}
+		}
+		return false
+	}
+
+	rangeOverObjects(list, func(object runtime.Object) {
+		if !matches(object.GetObjectKind().GroupVersionKind().Kind) {
+			return
+		}
+		if field, ok := replicasFieldOf(object); ok {
+			value := replicas
+			*field = &value
+		}
+	})
+}
+
+// RedactedValue replaces values of Secrets redacted by RedactSecrets
+const RedactedValue = "<redacted>"
+
+// RedactSecrets replaces every value in data and stringData of all Secrets with
+// RedactedValue, the keys are kept, so that the shape of Secrets can be reviewed
+func RedactSecrets(list *metav1.List) {
+	rangeOverObjects(list, func(object runtime.Object) {
+		secret, ok := object.(*corev1.Secret)
+		if !ok {
+			return
+		}
+		for key := range secret.Data {
+			secret.Data[key] = []byte(RedactedValue)
+		}
+		for key := range secret.StringData {
+			secret.StringData[key] = RedactedValue
+		}
+	})
+}
@@ -0,0 +1,26 @@
+package util
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ListFromUnstructured wraps objects (e.g. ones obtained from a dynamic client)
+// in a list, so that they can be encoded or written to files; nil objects are skipped
+func ListFromUnstructured(objs []*unstructured.Unstructured) *metav1.List {
+	list := &metav1.List{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "List",
+			APIVersion: "v1",
+		},
+	}
+	for _, obj := range objs {
+		if obj == nil {
+			continue
+		}
+		list.Items = append(list.Items, runtime.RawExtension{Object: obj})
+	}
+	return list
+}
@@ -3,22 +3,23 @@ package util
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
 	"path"
 
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/client-go/kubernetes/scheme"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/kubernetes/pkg/printers"
 
-	appsv1 "k8s.io/api/apps/v1"
-	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/ghodss/yaml"
 	"github.com/hashicorp/hcl"
 )
 
+// marshalToJSON doesn't go through a codec, so defaulting never happens
 func marshalToJSON(object runtime.Object) ([]byte, error) {
 	// TODO consider borrowing sorting code from pkg/kubectl/sorting_printer.go
 	jsprinter := printers.JSONPrinter{}
@@ -28,24 +29,200 @@ func marshalToJSON(object runtime.Object) ([]byte, error) {
 	}
 	return buf.Bytes(), nil
 }
-func Encode(object runtime.Object, contentType string, pretty bool) ([]byte, error) {
+
+// LineEnding is the line terminator used in encoded output
+type LineEnding int
+
+const (
+	LF LineEnding = iota
+	CRLF
+)
+
+func withLineEnding(data []byte, lineEnding LineEnding) []byte {
+	if lineEnding != CRLF {
+		return data
+	}
+	data = bytes.Replace(data, []byte("\r\n"), []byte("\n"), -1)
+	return bytes.Replace(data, []byte("\n"), []byte("\r\n"), -1)
+}
+
+// EncodeOptions control how objects get encoded
+type EncodeOptions struct {
+	// Pretty enables indentation of JSON output
+	Pretty bool
+	// Cleanup controls the cleanup pass
+	Cleanup CleanupOptions
+	// Minify removes every empty map, empty list and null value, not just the
+	// ones known to be meaningless; empty values that have a meaning, such as
+	// `emptyDir: {}`, are kept; the Cleanup.Only allowlist doesn't apply to it
+	Minify bool
+	// StripKeys lists paths of fields to remove from every object, e.g.
+	// `metadata.annotations["kubectl.kubernetes.io/last-applied-configuration"]`
+	StripKeys []string
+	// StripStatus removes the status of every object, as it's set by the
+	// cluster and shouldn't be kept in manifests
+	StripStatus bool
+	// APIVersion and Kind override what the codec sets on the encoded object,
+	// when encoding a list these apply to the list itself, not its items
+	APIVersion string
+	Kind       string
+	// QualifyAPIVersion expands abbreviated apiVersion of objects of non-core
+	// kinds, e.g. "v1" of a Deployment becomes "apps/v1"; core kinds keep "v1"
+	QualifyAPIVersion bool
+	// AnnotateGeneratedAt records the time of encoding in the "kubegen.io/generated-at"
+	// annotation of every object; creationTimestamp is still removed, as it's set by the cluster
+	AnnotateGeneratedAt bool
+	// FailIfEmpty makes EncodeListWithOptions and DumpListToFilesWithOptions
+	// return ErrEmptyList when there are no items in the list
+	FailIfEmpty bool
+	// FlowStyle lists paths of sequences of scalars to be written in flow
+	// style in YAML output, e.g. `spec.template.spec.containers[*].args`
+	FlowStyle []string
+	// FlowStyleForScalarSequences writes every sequence of scalars in flow style
+	FlowStyleForScalarSequences bool
+	// KeyOrder determines how keys are ordered, it's Alphabetical by default
+	KeyOrder KeyOrder
+	// MaxBytes limits the size of encoded output, encoding fails if the
+	// output would be larger; there is no limit when it's zero
+	MaxBytes int
+	// LineEnding is LF by default, CRLF can be used for Windows-based tools
+	LineEnding LineEnding
+	// AsList wraps an object that isn't a list into a one-item List, for
+	// consumers that only accept the List envelope
+	AsList bool
+	// Explain adds comments that explain common fields to YAML output, it's
+	// meant for learning and is labeled as such, there is no effect on JSON
+	Explain bool
+	// PostProcess is called with the final output, e.g. to run an external
+	// formatter or a redactor; for files it's called with the header included
+	PostProcess func([]byte) ([]byte, error)
+	// DefaultNamespace is set on objects of namespaced kinds that have no namespace,
+	// cluster-scoped objects, e.g. Namespaces, are left alone, and so are objects of
+	// custom resources, unless they have been registered with RegisterTypes
+	DefaultNamespace string
+}
+
+func encode(object runtime.Object, contentType string, opts EncodeOptions) ([]byte, error) {
+	if err := checkGeneratable(object); err != nil {
+		return nil, err
+	}
+	if opts.AsList {
+		object = asList(object)
+	}
 	data, err := marshalToJSON(object)
 	if err != nil {
 		return nil, err
 	}
-	return cleanup(contentType, data, pretty)
+	return encodeJSON(data, contentType, opts)
 }
 
-func EncodeList(list *metav1.List, contentType string, pretty bool) ([]byte, error) {
-	data, err := marshalToJSON(list)
+// encodeJSON takes an object that has been marshalled to JSON through
+// whatever means, and produces the final output
+func encodeJSON(data []byte, contentType string, opts EncodeOptions) ([]byte, error) {
+	data, err := cleanup(contentType, data, opts)
 	if err != nil {
 		return nil, err
 	}
-	return cleanup(contentType, data, pretty)
+	// output always ends with exactly one newline, JSON has none otherwise
+	data = append(bytes.TrimRight(data, "\n"), '\n')
+	data = withLineEnding(data, opts.LineEnding)
+	if opts.PostProcess != nil {
+		if data, err = opts.PostProcess(data); err != nil {
+			return nil, fmt.Errorf("kubegen/util: error post-processing output – %v", err)
+		}
+	}
+	if opts.MaxBytes > 0 && len(data) > opts.MaxBytes {
+		return nil, fmt.Errorf("kubegen/util: error encoding object – output is %d bytes, which exceeds the limit of %d bytes", len(data), opts.MaxBytes)
+	}
+	return data, nil
+}
+
+// Encode marshals object as it is, without applying any defaults of the scheme,
+// so fields like imagePullPolicy only appear in the output if they have been set
+func Encode(object runtime.Object, contentType string, pretty bool) ([]byte, error) {
+	return EncodeWithOptions(object, contentType, EncodeOptions{Pretty: pretty})
+}
+
+func EncodeWithOptions(object runtime.Object, contentType string, opts EncodeOptions) ([]byte, error) {
+	return encode(object, contentType, opts)
+}
+
+// EncodeAsList encodes an object wrapped into a one-item List, lists are encoded as they are
+func EncodeAsList(object runtime.Object, contentType string, pretty bool) ([]byte, error) {
+	return EncodeWithOptions(object, contentType, EncodeOptions{Pretty: pretty, AsList: true})
+}
+
+func asList(object runtime.Object) runtime.Object {
+	if meta.IsListType(object) {
+		return object
+	}
+	return &metav1.List{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "List",
+			APIVersion: "v1",
+		},
+		Items: []runtime.RawExtension{{Object: object}},
+	}
+}
+
+func EncodeList(list *metav1.List, contentType string, pretty bool) ([]byte, error) {
+	return EncodeListWithOptions(list, contentType, EncodeOptions{Pretty: pretty})
+}
+
+// ErrEmptyList is returned for lists that have no items, when FailIfEmpty is set
+var ErrEmptyList = errors.New("kubegen/util: list has no items")
+
+func EncodeListWithOptions(list *metav1.List, contentType string, opts EncodeOptions) ([]byte, error) {
+	if opts.FailIfEmpty && len(list.Items) == 0 {
+		return nil, ErrEmptyList
+	}
+	return encode(list, contentType, opts)
+}
+
+// EncodeMap encodes an object that has been built as a plain map, it
+// doesn't need to be a known kind, as it doesn't go through the codec
+func EncodeMap(obj map[string]interface{}, contentType string) ([]byte, error) {
+	return EncodeMapWithOptions(obj, contentType, EncodeOptions{})
+}
+
+func EncodeMapWithOptions(obj map[string]interface{}, contentType string, opts EncodeOptions) ([]byte, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("kubegen/util: error encoding map – %v", err)
+	}
+	return encodeJSON(data, contentType, opts)
 }
 
+// EncodeEach encodes every item of the list separately, the result is keyed by
+// a reference to each of the objects, e.g. "Deployment/default/web"
+func EncodeEach(list *metav1.List, contentType string, pretty bool) (map[string][]byte, error) {
+	output := make(map[string][]byte, len(list.Items))
+	for _, item := range list.Items {
+		key := describeObject(item.Object)
+		if _, ok := output[key]; ok {
+			return nil, fmt.Errorf("kubegen/util: error encoding %s – duplicate object", key)
+		}
+		data, err := Encode(item.Object, contentType, pretty)
+		if err != nil {
+			return nil, err
+		}
+		output[key] = data
+	}
+	return output, nil
+}
+
+// Decode returns a typed object for any kind known to the scheme, such as *corev1.Service,
+// objects of other kinds are returned as *unstructured.Unstructured
 func Decode(data []byte) (runtime.Object, error) {
-	obj, err := runtime.Decode(scheme.Codecs.UniversalDeserializer(), data)
+	data, _, err := maybeGunzip(data, "")
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := runtime.Decode(typesCodecs.UniversalDeserializer(), data)
+	if runtime.IsNotRegisteredError(err) {
+		return decodeUnstructured(data)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("kubegen/util: error decoding object– %v", err)
 	}
@@ -53,47 +230,138 @@ func Decode(data []byte) (runtime.Object, error) {
 	return obj, nil
 }
 
+// decodeUnstructured is what Decode falls back to for kinds that are not known to the scheme
+func decodeUnstructured(data []byte) (runtime.Object, error) {
+	jsonData, err := utilyaml.ToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("kubegen/util: error decoding object– %v", err)
+	}
+	obj, err := runtime.Decode(unstructured.UnstructuredJSONScheme, jsonData)
+	if err != nil {
+		return nil, fmt.Errorf("kubegen/util: error decoding object– %v", err)
+	}
+	return obj, nil
+}
+
+// DumpOptions control how DumpListToFiles writes files
+type DumpOptions struct {
+	// FS is where files get written to, the local filesystem is used by default
+	FS FileSystem
+	// Dir is the directory files get written to, by default it's the current directory
+	Dir string
+	// Encode controls how each object is encoded, JSON is always indented
+	Encode EncodeOptions
+	// Source is the manifest the objects were generated from, it is noted in the header of YAML files
+	Source string
+	// GroupByNamespace puts every object into a subdirectory named after its namespace,
+	// objects without a namespace go into the "_cluster" subdirectory
+	GroupByNamespace bool
+	// OmitDocumentSeparator removes the "---" that follows the header of YAML files
+	OmitDocumentSeparator bool
+	// HeaderTemplate is a text/template for the header of YAML files, it's
+	// executed with HeaderData; DefaultHeaderTemplate is used when it's empty
+	HeaderTemplate string
+	// Readme adds README.md with an overview of all of the files
+	Readme bool
+	// ReadmeTemplate is a text/template that ranges over a []ReadmeEntry,
+	// DefaultReadmeTemplate is used when it's empty
+	ReadmeTemplate string
+}
+
+// encodeFile encodes object the way it gets written to a file by DumpListToFiles
+func encodeFile(object runtime.Object, contentType, basename string, opts DumpOptions) ([]byte, error) {
+	encodeOpts := opts.Encode
+	encodeOpts.Pretty = true
+	// the header is part of the final output, so post-processing is done here
+	encodeOpts.PostProcess = nil
+	data, err := EncodeWithOptions(object, contentType, encodeOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	if contentType == "application/yaml" {
+		header, err := renderHeader(object, basename, opts)
+		if err != nil {
+			return nil, err
+		}
+		data = withLineEnding(append(header, data...), encodeOpts.LineEnding)
+	}
+
+	if postProcess := opts.Encode.PostProcess; postProcess != nil {
+		if data, err = postProcess(data); err != nil {
+			return nil, fmt.Errorf("kubegen/util: error post-processing %q – %v", basename, err)
+		}
+	}
+
+	return data, nil
+}
+
+// ClusterScopedDir is where GroupByNamespace puts objects that have no namespace
+const ClusterScopedDir = "_cluster"
+
+func namespaceDirFor(object runtime.Object, defaultNamespace string) string {
+	if namespace := namespaceOf(object); namespace != "" {
+		return namespace
+	}
+	if defaultNamespace != "" && isNamespacedKind(object.GetObjectKind().GroupVersionKind()) {
+		return defaultNamespace
+	}
+	return ClusterScopedDir
+}
+
 func DumpListToFiles(list *metav1.List, contentType string) ([]string, error) {
+	return DumpListToFilesWithOptions(list, contentType, DumpOptions{})
+}
+
+func DumpListToFilesWithOptions(list *metav1.List, contentType string, opts DumpOptions) ([]string, error) {
+	if opts.Encode.FailIfEmpty && len(list.Items) == 0 {
+		return nil, ErrEmptyList
+	}
+
+	fs := opts.FS
+	if fs == nil {
+		fs = OSFileSystem{}
+	}
+
 	filenames := []string{}
+	readme := []ReadmeEntry{}
 	for _, item := range list.Items {
-		var (
-			name, filename, filenamefmt string
-		)
-
 		i := item.Object
 
-		switch i.GetObjectKind().GroupVersionKind().Kind {
-		case "Service":
-			filenamefmt = "%s-svc.%s"
-			name = i.(*corev1.Service).ObjectMeta.Name
-		case "Deployment":
-			filenamefmt = "%s-dpl.%s"
-			name = i.(*appsv1.Deployment).ObjectMeta.Name
-		case "ReplicaSet":
-			filenamefmt = "%s-rs.%s"
-			name = i.(*appsv1.ReplicaSet).ObjectMeta.Name
-		case "DaemonSet":
-			filenamefmt = "%s-ds.%s"
-			name = i.(*appsv1.DaemonSet).ObjectMeta.Name
-		case "StatefulSet":
-			filenamefmt = "%s-ss.%s"
-			name = i.(*appsv1.StatefulSet).ObjectMeta.Name
+		basename, err := FileNameFor(i, contentType)
+		if err != nil {
+			return nil, err
+		}
+		relPath := basename
+		if opts.GroupByNamespace {
+			namespaceDir := namespaceDirFor(i, opts.Encode.DefaultNamespace)
+			relPath = path.Join(namespaceDir, basename)
+			dir := path.Join(opts.Dir, namespaceDir)
+			if err := fs.MkdirAll(dir, 0755); err != nil {
+				return nil, fmt.Errorf("kubegen/util: error creating output directory %q – %v", dir, err)
+			}
 		}
+		filename := path.Join(opts.Dir, relPath)
+		readme = append(readme, newReadmeEntry(i, relPath))
 
-		data, err := Encode(i, contentType, true)
+		data, err := encodeFile(i, contentType, basename, opts)
 		if err != nil {
 			return nil, err
 		}
 
-		switch contentType {
-		case "application/yaml":
-			filename = fmt.Sprintf(filenamefmt, name, "yaml")
-			data = append([]byte(fmt.Sprintf("# generated by kubegen\n# => %s\n---\n", filename)), data...)
-		case "application/json":
-			filename = fmt.Sprintf(filenamefmt, name, "yaml")
+		if err := fs.WriteFile(filename, data, 0644); err != nil {
+			return nil, fmt.Errorf("kubegen/util: error writing to file %q – %v", filename, err)
 		}
+		filenames = append(filenames, filename)
+	}
 
-		if err := ioutil.WriteFile(filename, data, 0644); err != nil {
+	if opts.Readme {
+		data, err := renderReadme(opts.ReadmeTemplate, readme)
+		if err != nil {
+			return nil, err
+		}
+		filename := path.Join(opts.Dir, ReadmeFileName)
+		if err := fs.WriteFile(filename, data, 0644); err != nil {
 			return nil, fmt.Errorf("kubegen/util: error writing to file %q – %v", filename, err)
 		}
 		filenames = append(filenames, filename)
@@ -124,6 +392,11 @@ func LoadObj(obj interface{}, data []byte, sourcePath string, instanceName strin
 		errorFmt = "kubegen/util: error loading manifest file"
 	}
 
+	data, sourcePath, err := maybeGunzip(data, sourcePath)
+	if err != nil {
+		return fmt.Errorf("%s %q – %v", errorFmt, sourcePath, err)
+	}
+
 	ext := path.Ext(sourcePath)
 	switch {
 	case ext == ".json":
@@ -0,0 +1,60 @@
+package util
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEncodeEndsWithOneNewline(t *testing.T) {
+	assert := assert.New(t)
+
+	service := &corev1.Service{
+		TypeMeta:   metav1.TypeMeta{Kind: "Service", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{Port: 80}},
+		},
+	}
+
+	for name, opts := range map[string]EncodeOptions{
+		"compact":           {},
+		"pretty":            {Pretty: true},
+		"minified":          {Minify: true},
+		"with key order":    {KeyOrder: AsIs},
+		"with flow style":   {FlowStyleForScalarSequences: true},
+		"with post-process": {PostProcess: func(data []byte) ([]byte, error) { return data, nil }},
+	} {
+		for _, contentType := range []string{"application/yaml", "application/json"} {
+			data, err := EncodeWithOptions(service, contentType, opts)
+			if !assert.NoError(err) {
+				continue
+			}
+			assert.True(bytes.HasSuffix(data, []byte("\n")), "%s %s output doesn't end with a newline", name, contentType)
+			assert.False(bytes.HasSuffix(data, []byte("\n\n")), "%s %s output ends with more than one newline", name, contentType)
+		}
+	}
+
+	data, err := EncodeWithOptions(service, "application/json", EncodeOptions{LineEnding: CRLF})
+	if assert.NoError(err) {
+		assert.True(bytes.HasSuffix(data, []byte("}\r\n")))
+	}
+
+	fs := NewMemoryFileSystem()
+	list := &metav1.List{Items: []runtime.RawExtension{{Object: service}}}
+	for _, contentType := range []string{"application/yaml", "application/json"} {
+		filenames, err := DumpListToFilesWithOptions(list, contentType, DumpOptions{FS: fs})
+		if !assert.NoError(err) || !assert.Len(filenames, 1) {
+			continue
+		}
+		data, _ := fs.ReadFile(filenames[0])
+		assert.True(bytes.HasSuffix(data, []byte("\n")))
+		assert.False(bytes.HasSuffix(data, []byte("\n\n")))
+	}
+}
@@ -0,0 +1,436 @@
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Warning describes a likely mistake found in a set of generated objects
+type Warning struct {
+	// Object refers to the object the warning is about, e.g. "Service/default/web"
+	Object  string
+	Message string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s: %s", w.Object, w.Message)
+}
+
+// CheckServiceSelectors warns about every Service that has a selector
+// that doesn't match pods of any of the workloads in the same list
+func CheckServiceSelectors(list *metav1.List) []Warning {
+	warnings := []Warning{}
+
+	rangeOverObjects(list, func(object runtime.Object) {
+		service, ok := object.(*corev1.Service)
+		if !ok || len(service.Spec.Selector) == 0 {
+			return
+		}
+
+		selector := labels.SelectorFromSet(labels.Set(service.Spec.Selector))
+		matched := false
+		rangeOverPods(list, func(workload runtime.Object, podMeta *metav1.ObjectMeta, _ *corev1.PodSpec) {
+			if namespaceOf(workload) != service.Namespace {
+				return
+			}
+			if selector.Matches(labels.Set(podMeta.Labels)) {
+				matched = true
+			}
+		})
+
+		if !matched {
+			warnings = append(warnings, Warning{
+				Object:  describeObject(service),
+				Message: fmt.Sprintf("selector %q doesn't match pods of any workload", selector.String()),
+			})
+		}
+	})
+
+	return warnings
+}
+
+// CheckReferences warns about every ConfigMap or Secret that pods of a workload
+// refer to, but which is not in the same list; references marked as optional
+// are assumed to be satisfied by objects that are managed elsewhere
+func CheckReferences(list *metav1.List) []Warning {
+	warnings := []Warning{}
+
+	defined := make(map[string]bool)
+	rangeOverObjects(list, func(object runtime.Object) {
+		switch object.(type) {
+		case *corev1.ConfigMap, *corev1.Secret:
+			defined[describeObject(object)] = true
+		}
+	})
+
+	rangeOverPods(list, func(workload runtime.Object, _ *metav1.ObjectMeta, podSpec *corev1.PodSpec) {
+		namespace := namespaceOf(workload)
+		reported := make(map[string]bool)
+		rangeOverPodReferences(podSpec, func(kind string, name *string, optional bool) {
+			if optional || *name == "" {
+				return
+			}
+			ref := kind + "/" + *name
+			if namespace != "" {
+				ref = kind + "/" + namespace + "/" + *name
+			}
+			if defined[ref] || reported[ref] {
+				return
+			}
+			reported[ref] = true
+			warnings = append(warnings, Warning{
+				Object:  describeObject(workload),
+				Message: fmt.Sprintf("refers to %s %q, which is not defined", kind, *name),
+			})
+		})
+	})
+
+	return warnings
+}
+
+// CheckServiceTargetPorts warns about every port of a Service that has a targetPort
+// that none of the containers of the workloads matched by the selector expose, a port
+// without targetPort is checked as well, since the port number is used in that case
+func CheckServiceTargetPorts(list *metav1.List) []Warning {
+	warnings := []Warning{}
+
+	rangeOverObjects(list, func(object runtime.Object) {
+		service, ok := object.(*corev1.Service)
+		if !ok || len(service.Spec.Selector) == 0 {
+			return
+		}
+
+		selector := labels.SelectorFromSet(labels.Set(service.Spec.Selector))
+		containerPorts := []corev1.ContainerPort{}
+		matched := false
+		rangeOverPods(list, func(workload runtime.Object, podMeta *metav1.ObjectMeta, podSpec *corev1.PodSpec) {
+			if namespaceOf(workload) != service.Namespace || !selector.Matches(labels.Set(podMeta.Labels)) {
+				return
+			}
+			matched = true
+			rangeOverContainers(podSpec, func(container *corev1.Container) {
+				containerPorts = append(containerPorts, container.Ports...)
+			})
+		})
+		// CheckServiceSelectors reports services that match nothing
+		if !matched {
+			return
+		}
+
+		protocolOf := func(protocol corev1.Protocol) corev1.Protocol {
+			if protocol == "" {
+				return corev1.ProtocolTCP
+			}
+			return protocol
+		}
+
+		for _, port := range service.Spec.Ports {
+			targetPort := port.TargetPort
+			if targetPort.Type == intstr.Int && targetPort.IntVal == 0 {
+				targetPort = intstr.FromInt(int(port.Port))
+			}
+
+			exposed := false
+			for _, containerPort := range containerPorts {
+				if protocolOf(containerPort.Protocol) != protocolOf(port.Protocol) {
+					continue
+				}
+				switch targetPort.Type {
+				case intstr.Int:
+					exposed = exposed || containerPort.ContainerPort == targetPort.IntVal
+				case intstr.String:
+					exposed = exposed || containerPort.Name == targetPort.StrVal
+				}
+			}
+
+			if !exposed {
+				warnings = append(warnings, Warning{
+					Object:  describeObject(service),
+					Message: fmt.Sprintf("target port %q of port %d is not exposed by any container of the selected workloads", targetPort.String(), port.Port),
+				})
+			}
+		}
+	})
+
+	return warnings
+}
+
+// ValidationError lists every problem Validate or ValidateList has found
+type ValidationError struct {
+	Problems []Warning
+}
+
+func (e *ValidationError) Error() string {
+	problems := make([]string, len(e.Problems))
+	for n, problem := range e.Problems {
+		problems[n] = problem.String()
+	}
+	return fmt.Sprintf("kubegen/util: invalid objects – %s", strings.Join(problems, "; "))
+}
+
+// validators check an object for things that would cause the API server to reject it
+var validators = []func(object runtime.Object) []string{
+	validateName,
+	validateSelector,
+	validateLabelsAndAnnotations,
+}
+
+// validateName checks name of the object follows the rules the API server imposes on
+// objects of its kind, for most kinds it has to be a DNS subdomain (RFC 1123), but
+// RBAC objects only need to be valid path segments (e.g. "system:metrics-reader")
+func validateName(object runtime.Object) []string {
+	objectMeta, err := meta.Accessor(object)
+	if err != nil {
+		return nil
+	}
+
+	name := objectMeta.GetName()
+	if name == "" {
+		if objectMeta.GetGenerateName() != "" {
+			return nil
+		}
+		return []string{"name is empty"}
+	}
+
+	var problems []string
+	switch object.GetObjectKind().GroupVersionKind().Kind {
+	case "Service":
+		problems = validation.IsDNS1035Label(name)
+	case "Namespace":
+		problems = validation.IsDNS1123Label(name)
+	case "ClusterRole", "ClusterRoleBinding", "Role", "RoleBinding":
+		problems = isPathSegmentName(name)
+	default:
+		problems = validation.IsDNS1123Subdomain(name)
+	}
+
+	messages := []string{}
+	for _, problem := range problems {
+		messages = append(messages, fmt.Sprintf("invalid name %q – %s", name, problem))
+	}
+	return messages
+}
+
+// isPathSegmentName mirrors path segment name validation of the API server
+func isPathSegmentName(name string) []string {
+	if name == "." || name == ".." {
+		return []string{fmt.Sprintf("may not be %q", name)}
+	}
+	problems := []string{}
+	for _, illegal := range []string{"/", "%"} {
+		if strings.Contains(name, illegal) {
+			problems = append(problems, fmt.Sprintf("may not contain %q", illegal))
+		}
+	}
+	return problems
+}
+
+// validateSelector checks that pods of a workload match its own selector
+func validateSelector(object runtime.Object) []string {
+	selector, ok := selectorOf(object)
+	if !ok {
+		return nil
+	}
+	podMeta, _, ok := podOf(object)
+	if !ok {
+		return nil
+	}
+
+	problems := []string{}
+
+	// sorted, to make the output stable
+	keys := []string{}
+	for key := range selector.MatchLabels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value, ok := podMeta.Labels[key]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("label %q of the selector is missing from the pod template", key))
+		case value != selector.MatchLabels[key]:
+			problems = append(problems, fmt.Sprintf("label %q of the pod template is %q, but the selector expects %q", key, value, selector.MatchLabels[key]))
+		}
+	}
+
+	if len(problems) == 0 && len(selector.MatchExpressions) != 0 {
+		s, err := metav1.LabelSelectorAsSelector(selector)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("invalid selector – %v", err))
+		} else if !s.Matches(labels.Set(podMeta.Labels)) {
+			problems = append(problems, fmt.Sprintf("selector %q doesn't match labels of the pod template", s.String()))
+		}
+	}
+
+	return problems
+}
+
+// totalAnnotationSizeLimit is how large annotations of an object can be altogether
+const totalAnnotationSizeLimit = 256 * (1 << 10)
+
+func sortedStringKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func validateObjectMeta(objectMeta metav1.Object, where string) []string {
+	problems := []string{}
+
+	labelSet := objectMeta.GetLabels()
+	for _, key := range sortedStringKeys(labelSet) {
+		for _, problem := range validation.IsQualifiedName(key) {
+			problems = append(problems, fmt.Sprintf("invalid label key %q%s – %s", key, where, problem))
+		}
+		for _, problem := range validation.IsValidLabelValue(labelSet[key]) {
+			problems = append(problems, fmt.Sprintf("invalid value %q of label %q%s – %s", labelSet[key], key, where, problem))
+		}
+	}
+
+	annotations := objectMeta.GetAnnotations()
+	size := 0
+	for _, key := range sortedStringKeys(annotations) {
+		for _, problem := range validation.IsQualifiedName(strings.ToLower(key)) {
+			problems = append(problems, fmt.Sprintf("invalid annotation key %q%s – %s", key, where, problem))
+		}
+		size += len(key) + len(annotations[key])
+	}
+	if size > totalAnnotationSizeLimit {
+		problems = append(problems, fmt.Sprintf("annotations%s are %d bytes, which exceeds the limit of %d bytes", where, size, totalAnnotationSizeLimit))
+	}
+
+	return problems
+}
+
+// validateLabelsAndAnnotations checks keys and values of labels, and keys and total
+// size of annotations, of the object itself, as well as its pod template
+func validateLabelsAndAnnotations(object runtime.Object) []string {
+	objectMeta, err := meta.Accessor(object)
+	if err != nil {
+		return nil
+	}
+	problems := validateObjectMeta(objectMeta, "")
+	if _, ok := object.(*corev1.Pod); ok {
+		return problems
+	}
+	if podMeta, _, ok := podOf(object); ok {
+		problems = append(problems, validateObjectMeta(podMeta, " of the pod template")...)
+	}
+	return problems
+}
+
+// limits beyond which quantities are most likely a typo, e.g. "500" cores instead of "500m"
+var (
+	maxSensibleCPU    = resource.MustParse("256")
+	minSensibleMemory = resource.MustParse("1Mi")
+)
+
+// CheckResourceQuantities warns about requests and limits of every container that
+// are valid quantities, but are almost certainly not what was meant, such as memory
+// in millibytes ("512m" instead of "512Mi") or thousands of cores ("500" instead of "500m")
+func CheckResourceQuantities(list *metav1.List) []Warning {
+	warnings := []Warning{}
+
+	rangeOverPods(list, func(workload runtime.Object, _ *metav1.ObjectMeta, podSpec *corev1.PodSpec) {
+		warn := func(format string, args ...interface{}) {
+			warnings = append(warnings, Warning{
+				Object:  describeObject(workload),
+				Message: fmt.Sprintf(format, args...),
+			})
+		}
+
+		rangeOverContainers(podSpec, func(container *corev1.Container) {
+			for _, resources := range []struct {
+				listName string
+				list     corev1.ResourceList
+			}{
+				{"requests", container.Resources.Requests},
+				{"limits", container.Resources.Limits},
+			} {
+				listName, list := resources.listName, resources.list
+				if quantity, ok := list[corev1.ResourceMemory]; ok {
+					value := quantity.String()
+					switch {
+					case quantity.MilliValue()%1000 != 0:
+						warn("memory %s %q of container %q is in millibytes, did you mean %q?", listName, value, container.Name, strings.TrimSuffix(value, "m")+"Mi")
+					case quantity.Sign() > 0 && quantity.Cmp(minSensibleMemory) < 0:
+						warn("memory %s %q of container %q is less than 1Mi, a unit suffix is probably missing", listName, value, container.Name)
+					}
+				}
+				if quantity, ok := list[corev1.ResourceCPU]; ok {
+					if quantity.Cmp(maxSensibleCPU) > 0 {
+						warn("cpu %s %q of container %q is more than %s cores, did you mean %q?", listName, quantity.String(), container.Name, maxSensibleCPU.String(), quantity.String()+"m")
+					}
+				}
+			}
+		})
+	})
+
+	return warnings
+}
+
+// ValidateOptions enable stricter checks in addition to what Validate checks
+type ValidateOptions struct {
+	// RequireImageDigests rejects containers with images that are not pinned by digest
+	RequireImageDigests bool
+}
+
+func validate(object runtime.Object, opts ValidateOptions) []Warning {
+	problems := []Warning{}
+	report := func(messages []string) {
+		for _, message := range messages {
+			problems = append(problems, Warning{Object: describeObject(object), Message: message})
+		}
+	}
+	for _, validator := range validators {
+		report(validator(object))
+	}
+	if _, podSpec, ok := podOf(object); ok && opts.RequireImageDigests {
+		report(imageDigestProblems(podSpec))
+	}
+	return problems
+}
+
+// Validate checks object for mistakes that would cause the API server to reject it,
+// the error is a *ValidationError that lists all of the problems found
+func Validate(object runtime.Object) error {
+	return ValidateWithOptions(object, ValidateOptions{})
+}
+
+func ValidateWithOptions(object runtime.Object, opts ValidateOptions) error {
+	if problems := validate(object, opts); len(problems) != 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// ValidateList is like Validate, but checks every object in the list
+func ValidateList(list *metav1.List) error {
+	return ValidateListWithOptions(list, ValidateOptions{})
+}
+
+func ValidateListWithOptions(list *metav1.List, opts ValidateOptions) error {
+	problems := []Warning{}
+	rangeOverObjects(list, func(object runtime.Object) {
+		problems = append(problems, validate(object, opts)...)
+	})
+	if len(problems) != 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
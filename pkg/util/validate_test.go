@@ -0,0 +1,99 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateName(t *testing.T) {
+	clusterRole := func(name string) *rbacv1.ClusterRole {
+		return &rbacv1.ClusterRole{
+			TypeMeta:   metav1.TypeMeta{Kind: "ClusterRole", APIVersion: "rbac.authorization.k8s.io/v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+		}
+	}
+	roleBinding := func(name string) *rbacv1.RoleBinding {
+		return &rbacv1.RoleBinding{
+			TypeMeta:   metav1.TypeMeta{Kind: "RoleBinding", APIVersion: "rbac.authorization.k8s.io/v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			RoleRef:    rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "Role", Name: "reader"},
+		}
+	}
+	configMap := func(name string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		}
+	}
+
+	for _, name := range []string{"system:metrics-reader", "system:controller:Node_Admin", "reader"} {
+		assert.NoError(t, Validate(clusterRole(name)), name)
+		assert.NoError(t, Validate(roleBinding(name)), name)
+	}
+	for _, name := range []string{".", "..", "a/b", "100%"} {
+		assert.Error(t, Validate(clusterRole(name)), name)
+		assert.Error(t, Validate(roleBinding(name)), name)
+	}
+
+	assert.NoError(t, Validate(configMap("web.config")))
+	assert.Error(t, Validate(configMap("system:metrics-reader")))
+}
+
+func TestCheckResourceQuantities(t *testing.T) {
+	assert := assert.New(t)
+
+	pod := &corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  "web",
+					Image: "nginx",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceMemory: resource.MustParse("512m"),
+							corev1.ResourceCPU:    resource.MustParse("500"),
+						},
+						Limits: corev1.ResourceList{
+							corev1.ResourceMemory: resource.MustParse("512"),
+						},
+					},
+				},
+				{
+					Name:  "sidecar",
+					Image: "busybox",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceMemory: resource.MustParse("64Mi"),
+							corev1.ResourceCPU:    resource.MustParse("100m"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	list := &metav1.List{Items: []runtime.RawExtension{{Object: pod}}}
+	warnings := CheckResourceQuantities(list)
+	if assert.Len(warnings, 3) {
+		for _, warning := range warnings {
+			assert.Equal("Pod/default/web", warning.Object)
+			assert.Contains(warning.Message, `container "web"`)
+		}
+		assert.Contains(warnings[0].Message, `did you mean "512Mi"?`)
+		assert.Contains(warnings[1].Message, `did you mean "500m"?`)
+		assert.Contains(warnings[2].Message, "less than 1Mi")
+	}
+
+	// these are only a heuristic, so they don't make an object invalid
+	assert.NoError(Validate(pod))
+}
@@ -0,0 +1,165 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kubernetes/pkg/api/legacyscheme"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type groupVersionSince struct {
+	minor        int
+	groupVersion schema.GroupVersion
+}
+
+var (
+	appsV1             = schema.GroupVersion{Group: "apps", Version: "v1"}
+	appsV1beta2        = schema.GroupVersion{Group: "apps", Version: "v1beta2"}
+	appsV1beta1        = schema.GroupVersion{Group: "apps", Version: "v1beta1"}
+	extensionsV1beta1  = schema.GroupVersion{Group: "extensions", Version: "v1beta1"}
+	groupVersionByKind = map[string][]groupVersionSince{
+		// each table must be sorted from newest to oldest
+		"Deployment":  {{9, appsV1}, {8, appsV1beta2}, {6, appsV1beta1}, {0, extensionsV1beta1}},
+		"DaemonSet":   {{9, appsV1}, {8, appsV1beta2}, {0, extensionsV1beta1}},
+		"ReplicaSet":  {{9, appsV1}, {8, appsV1beta2}, {0, extensionsV1beta1}},
+		"StatefulSet": {{9, appsV1}, {8, appsV1beta2}, {0, appsV1beta1}},
+	}
+)
+
+func parseKubernetesVersion(k8sVersion string) (int, int, error) {
+	parts := strings.SplitN(strings.TrimPrefix(k8sVersion, "v"), ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, fmt.Errorf("kubegen/util: invalid Kubernetes version %q, expected \"<major>.<minor>\"", k8sVersion)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("kubegen/util: invalid major version in %q – %v", k8sVersion, err)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("kubegen/util: invalid minor version in %q – %v", k8sVersion, err)
+	}
+	return major, minor, nil
+}
+
+// groupVersionFor returns the group version a given kind should be
+// encoded in for a given version of Kubernetes, if the kind is not known
+// to have moved between groups, the group version of object is returned
+func groupVersionFor(object runtime.Object, k8sVersion string) (schema.GroupVersion, error) {
+	gvk := object.GetObjectKind().GroupVersionKind()
+
+	major, minor, err := parseKubernetesVersion(k8sVersion)
+	if err != nil {
+		return schema.GroupVersion{}, err
+	}
+	if major != 1 {
+		return schema.GroupVersion{}, fmt.Errorf("kubegen/util: unsupported Kubernetes version %q", k8sVersion)
+	}
+
+	for _, candidate := range groupVersionByKind[gvk.Kind] {
+		if minor >= candidate.minor {
+			return candidate.groupVersion, nil
+		}
+	}
+
+	return gvk.GroupVersion(), nil
+}
+
+// convertViaJSON converts object into a different group version of the same
+// type by decoding its JSON representation, this works for kinds that
+// moved between groups without any significant changes to their schema
+func convertViaJSON(object runtime.Object, gvk schema.GroupVersionKind) (runtime.Object, error) {
+	if object.GetObjectKind().GroupVersionKind() == gvk {
+		return object, nil
+	}
+
+	converted, err := typesScheme.New(gvk)
+	if err != nil {
+		return nil, fmt.Errorf("kubegen/util: error converting object to %s – %v", gvk, err)
+	}
+
+	data, err := json.Marshal(object)
+	if err != nil {
+		return nil, fmt.Errorf("kubegen/util: error converting object to %s – %v", gvk, err)
+	}
+	if err := json.Unmarshal(data, converted); err != nil {
+		return nil, fmt.Errorf("kubegen/util: error converting object to %s – %v", gvk, err)
+	}
+
+	converted.GetObjectKind().SetGroupVersionKind(gvk)
+	return converted, nil
+}
+
+// convertForVersion converts object into gvk using conversion functions of Kubernetes
+// where they are registered, and falls back to convertViaJSON otherwise
+func convertForVersion(object runtime.Object, gvk schema.GroupVersionKind) (runtime.Object, error) {
+	source := object.GetObjectKind().GroupVersionKind()
+	if source == gvk {
+		return object, nil
+	}
+
+	var (
+		converted runtime.Object
+		err       error
+	)
+	if legacyscheme.Scheme.Recognizes(source) && legacyscheme.Scheme.Recognizes(gvk) {
+		converted, err = ConvertObject(object, gvk)
+	} else {
+		converted, err = convertViaJSON(object, gvk)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// internal types have no notion of unset replicas, so conversion turns them into 0,
+	// while the server would default them to 1
+	if replicas, ok := replicasFieldOf(object); ok && *replicas == nil {
+		if convertedReplicas, ok := replicasFieldOf(converted); ok {
+			*convertedReplicas = nil
+		}
+	}
+	defaultSelector(converted)
+	return converted, nil
+}
+
+// defaultSelector sets the selector of a workload object that has none to the labels
+// of its pod template, older group versions default to this on the server, but newer
+// ones (e.g. apps/v1) require the selector to be set
+func defaultSelector(object runtime.Object) {
+	selector, ok := selectorFieldOf(object)
+	if !ok || *selector != nil {
+		return
+	}
+	template, ok := podTemplateOf(object)
+	if !ok || len(template.Labels) == 0 {
+		return
+	}
+
+	matchLabels := make(map[string]string, len(template.Labels))
+	for k, v := range template.Labels {
+		matchLabels[k] = v
+	}
+	*selector = &metav1.LabelSelector{MatchLabels: matchLabels}
+}
+
+// EncodeForVersion is like Encode, but it converts object to the group version
+// most appropriate for the given version of Kubernetes (e.g. "1.7") first
+func EncodeForVersion(object runtime.Object, contentType string, k8sVersion string, pretty bool) ([]byte, error) {
+	groupVersion, err := groupVersionFor(object, k8sVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	converted, err := convertForVersion(object, groupVersion.WithKind(object.GetObjectKind().GroupVersionKind().Kind))
+	if err != nil {
+		return nil, err
+	}
+
+	return Encode(converted, contentType, pretty)
+}
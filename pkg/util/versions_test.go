@@ -0,0 +1,84 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	appsv1 "k8s.io/api/apps/v1"
+	appsv1beta1 "k8s.io/api/apps/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newVersionsTestDeployment() *extensionsv1beta1.Deployment {
+	return &extensionsv1beta1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "extensions/v1beta1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: extensionsv1beta1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "web", Image: "nginx"}},
+				},
+			},
+		},
+	}
+}
+
+func TestEncodeForVersion(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		k8sVersion string
+		apiVersion string
+		into       interface{}
+	}{
+		{"1.9", "apps/v1", &appsv1.Deployment{}},
+		{"1.7", "apps/v1beta1", &appsv1beta1.Deployment{}},
+		{"1.5", "extensions/v1beta1", &extensionsv1beta1.Deployment{}},
+	}
+
+	for _, test := range tests {
+		dep := newVersionsTestDeployment()
+		data, err := EncodeForVersion(dep, "application/json", test.k8sVersion, false)
+		if !assert.NoError(err, test.k8sVersion) {
+			continue
+		}
+		assert.Equal(newVersionsTestDeployment(), dep, "%s: input object was modified", test.k8sVersion)
+		assert.Contains(string(data), `"apiVersion":"`+test.apiVersion+`"`, test.k8sVersion)
+		assert.NotContains(string(data), `"securityContext"`, test.k8sVersion)
+		assert.NotContains(string(data), `"replicas"`, test.k8sVersion)
+
+		obj, err := Decode(data)
+		if !assert.NoError(err, test.k8sVersion) {
+			continue
+		}
+		selector, ok := selectorOf(obj)
+		if test.apiVersion == "extensions/v1beta1" {
+			assert.False(ok, "%s: selector shouldn't be added without conversion", test.k8sVersion)
+			continue
+		}
+		if assert.True(ok, "%s: selector is missing", test.k8sVersion) {
+			assert.Equal(map[string]string{"app": "web"}, selector.MatchLabels, test.k8sVersion)
+		}
+		if template, ok := podTemplateOf(obj); assert.True(ok, test.k8sVersion) {
+			assert.Equal("nginx", template.Spec.Containers[0].Image, test.k8sVersion)
+		}
+	}
+
+	dep := newVersionsTestDeployment()
+	dep.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web", "tier": "frontend"}}
+	dep.Spec.Template.Labels["tier"] = "frontend"
+	data, err := EncodeForVersion(dep, "application/json", "1.9", false)
+	if assert.NoError(err) {
+		obj, err := Decode(data)
+		if assert.NoError(err) {
+			assert.Equal(dep.Spec.Selector, obj.(*appsv1.Deployment).Spec.Selector, "explicit selector must be kept")
+		}
+	}
+
+	_, err = EncodeForVersion(dep, "application/json", "2.0", false)
+	assert.Error(err)
+}
@@ -0,0 +1,316 @@
+package util
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	appsv1 "k8s.io/api/apps/v1"
+	appsv1beta1 "k8s.io/api/apps/v1beta1"
+	appsv1beta2 "k8s.io/api/apps/v1beta2"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// podOf returns metadata and spec of the pods a workload object runs,
+// for a Pod these are its own metadata and spec
+func podOf(object runtime.Object) (*metav1.ObjectMeta, *corev1.PodSpec, bool) {
+	if pod, ok := object.(*corev1.Pod); ok {
+		return &pod.ObjectMeta, &pod.Spec, true
+	}
+	template, ok := podTemplateOf(object)
+	if !ok {
+		return nil, nil, false
+	}
+	return &template.ObjectMeta, &template.Spec, true
+}
+
+// podTemplateOf returns the pod template of a workload object
+func podTemplateOf(object runtime.Object) (*corev1.PodTemplateSpec, bool) {
+	var template *corev1.PodTemplateSpec
+
+	switch o := object.(type) {
+	case *corev1.PodTemplate:
+		template = &o.Template
+	case *corev1.ReplicationController:
+		template = o.Spec.Template
+	case *appsv1.Deployment:
+		template = &o.Spec.Template
+	case *appsv1.ReplicaSet:
+		template = &o.Spec.Template
+	case *appsv1.DaemonSet:
+		template = &o.Spec.Template
+	case *appsv1.StatefulSet:
+		template = &o.Spec.Template
+	case *appsv1beta2.Deployment:
+		template = &o.Spec.Template
+	case *appsv1beta2.ReplicaSet:
+		template = &o.Spec.Template
+	case *appsv1beta2.DaemonSet:
+		template = &o.Spec.Template
+	case *appsv1beta2.StatefulSet:
+		template = &o.Spec.Template
+	case *appsv1beta1.Deployment:
+		template = &o.Spec.Template
+	case *appsv1beta1.StatefulSet:
+		template = &o.Spec.Template
+	case *extensionsv1beta1.Deployment:
+		template = &o.Spec.Template
+	case *extensionsv1beta1.ReplicaSet:
+		template = &o.Spec.Template
+	case *extensionsv1beta1.DaemonSet:
+		template = &o.Spec.Template
+	case *batchv1.Job:
+		template = &o.Spec.Template
+	case *batchv1beta1.CronJob:
+		template = &o.Spec.JobTemplate.Spec.Template
+	}
+
+	return template, template != nil
+}
+
+// ExtractPodTemplate returns a copy of the pod template of any workload kind,
+// for a Pod the template is made of its labels, annotations and spec
+func ExtractPodTemplate(object runtime.Object) (*corev1.PodTemplateSpec, error) {
+	if pod, ok := object.(*corev1.Pod); ok {
+		pod = pod.DeepCopy()
+		return &corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:      pod.Labels,
+				Annotations: pod.Annotations,
+			},
+			Spec: pod.Spec,
+		}, nil
+	}
+	template, ok := podTemplateOf(object)
+	if !ok {
+		return nil, fmt.Errorf("kubegen/util: unable to extract pod template – %s is not a workload", describeObject(object))
+	}
+	return template.DeepCopy(), nil
+}
+
+// selectorOf returns the label selector of a workload object that manages
+// pods by labels, selector of a ReplicationController is converted
+func selectorOf(object runtime.Object) (*metav1.LabelSelector, bool) {
+	var selector *metav1.LabelSelector
+
+	switch o := object.(type) {
+	case *corev1.ReplicationController:
+		if o.Spec.Selector != nil {
+			selector = &metav1.LabelSelector{MatchLabels: o.Spec.Selector}
+		}
+	case *appsv1.Deployment:
+		selector = o.Spec.Selector
+	case *appsv1.ReplicaSet:
+		selector = o.Spec.Selector
+	case *appsv1.DaemonSet:
+		selector = o.Spec.Selector
+	case *appsv1.StatefulSet:
+		selector = o.Spec.Selector
+	case *appsv1beta2.Deployment:
+		selector = o.Spec.Selector
+	case *appsv1beta2.ReplicaSet:
+		selector = o.Spec.Selector
+	case *appsv1beta2.DaemonSet:
+		selector = o.Spec.Selector
+	case *appsv1beta2.StatefulSet:
+		selector = o.Spec.Selector
+	case *appsv1beta1.Deployment:
+		selector = o.Spec.Selector
+	case *appsv1beta1.StatefulSet:
+		selector = o.Spec.Selector
+	case *extensionsv1beta1.Deployment:
+		selector = o.Spec.Selector
+	case *extensionsv1beta1.ReplicaSet:
+		selector = o.Spec.Selector
+	case *extensionsv1beta1.DaemonSet:
+		selector = o.Spec.Selector
+	}
+
+	return selector, selector != nil
+}
+
+// selectorFieldOf returns a pointer to spec.selector of a workload object that
+// has a label selector, i.e. of everything selectorOf handles but ReplicationControllers
+func selectorFieldOf(object runtime.Object) (**metav1.LabelSelector, bool) {
+	switch o := object.(type) {
+	case *appsv1.Deployment:
+		return &o.Spec.Selector, true
+	case *appsv1.ReplicaSet:
+		return &o.Spec.Selector, true
+	case *appsv1.DaemonSet:
+		return &o.Spec.Selector, true
+	case *appsv1.StatefulSet:
+		return &o.Spec.Selector, true
+	case *appsv1beta2.Deployment:
+		return &o.Spec.Selector, true
+	case *appsv1beta2.ReplicaSet:
+		return &o.Spec.Selector, true
+	case *appsv1beta2.DaemonSet:
+		return &o.Spec.Selector, true
+	case *appsv1beta2.StatefulSet:
+		return &o.Spec.Selector, true
+	case *appsv1beta1.Deployment:
+		return &o.Spec.Selector, true
+	case *appsv1beta1.StatefulSet:
+		return &o.Spec.Selector, true
+	case *extensionsv1beta1.Deployment:
+		return &o.Spec.Selector, true
+	case *extensionsv1beta1.ReplicaSet:
+		return &o.Spec.Selector, true
+	case *extensionsv1beta1.DaemonSet:
+		return &o.Spec.Selector, true
+	}
+	return nil, false
+}
+
+// replicasFieldOf returns a pointer to spec.replicas of a workload object that has it
+func replicasFieldOf(object runtime.Object) (**int32, bool) {
+	switch o := object.(type) {
+	case *corev1.ReplicationController:
+		return &o.Spec.Replicas, true
+	case *appsv1.Deployment:
+		return &o.Spec.Replicas, true
+	case *appsv1.ReplicaSet:
+		return &o.Spec.Replicas, true
+	case *appsv1.StatefulSet:
+		return &o.Spec.Replicas, true
+	case *appsv1beta2.Deployment:
+		return &o.Spec.Replicas, true
+	case *appsv1beta2.ReplicaSet:
+		return &o.Spec.Replicas, true
+	case *appsv1beta2.StatefulSet:
+		return &o.Spec.Replicas, true
+	case *appsv1beta1.Deployment:
+		return &o.Spec.Replicas, true
+	case *appsv1beta1.StatefulSet:
+		return &o.Spec.Replicas, true
+	case *extensionsv1beta1.Deployment:
+		return &o.Spec.Replicas, true
+	case *extensionsv1beta1.ReplicaSet:
+		return &o.Spec.Replicas, true
+	}
+	return nil, false
+}
+
+// replicasOf returns how many pods a workload runs, defaulting to 1 when it's not set,
+// for a DaemonSet it depends on the number of nodes, so 1 is returned as well
+func replicasOf(object runtime.Object) int64 {
+	var replicas *int32
+
+	if field, ok := replicasFieldOf(object); ok {
+		replicas = *field
+	} else if job, ok := object.(*batchv1.Job); ok {
+		replicas = job.Spec.Parallelism
+	}
+
+	if replicas == nil {
+		return 1
+	}
+	return int64(*replicas)
+}
+
+// rangeOverContainers calls iter for every init container and container in spec
+func rangeOverContainers(spec *corev1.PodSpec, iter func(container *corev1.Container)) {
+	for n := range spec.InitContainers {
+		iter(&spec.InitContainers[n])
+	}
+	for n := range spec.Containers {
+		iter(&spec.Containers[n])
+	}
+}
+
+// rangeOverObjects calls iter for every item in the list that holds a decoded object
+func rangeOverObjects(list *metav1.List, iter func(object runtime.Object)) {
+	for _, item := range list.Items {
+		if item.Object != nil {
+			iter(item.Object)
+		}
+	}
+}
+
+// rangeOverPods calls iter for every workload object in the list
+func rangeOverPods(list *metav1.List, iter func(object runtime.Object, podMeta *metav1.ObjectMeta, podSpec *corev1.PodSpec)) {
+	rangeOverObjects(list, func(object runtime.Object) {
+		if podMeta, podSpec, ok := podOf(object); ok {
+			iter(object, podMeta, podSpec)
+		}
+	})
+}
+
+// describeObject returns a short reference to the object, e.g. "Service/default/web"
+func describeObject(object runtime.Object) string {
+	kind := object.GetObjectKind().GroupVersionKind().Kind
+	objectMeta, err := meta.Accessor(object)
+	if err != nil {
+		return kind
+	}
+	if namespace := objectMeta.GetNamespace(); namespace != "" {
+		return fmt.Sprintf("%s/%s/%s", kind, namespace, objectMeta.GetName())
+	}
+	return fmt.Sprintf("%s/%s", kind, objectMeta.GetName())
+}
+
+func namespaceOf(object runtime.Object) string {
+	if objectMeta, err := meta.Accessor(object); err == nil {
+		return objectMeta.GetNamespace()
+	}
+	return ""
+}
+
+func isOptional(optional *bool) bool {
+	return optional != nil && *optional
+}
+
+// rangeOverPodReferences calls iter with the name of every ConfigMap and
+// Secret that spec refers to, the name can be modified through the pointer
+func rangeOverPodReferences(spec *corev1.PodSpec, iter func(kind string, name *string, optional bool)) {
+	for n := range spec.Volumes {
+		source := &spec.Volumes[n].VolumeSource
+		if source.ConfigMap != nil {
+			iter("ConfigMap", &source.ConfigMap.Name, isOptional(source.ConfigMap.Optional))
+		}
+		if source.Secret != nil {
+			iter("Secret", &source.Secret.SecretName, isOptional(source.Secret.Optional))
+		}
+		if source.Projected != nil {
+			for m := range source.Projected.Sources {
+				projection := &source.Projected.Sources[m]
+				if projection.ConfigMap != nil {
+					iter("ConfigMap", &projection.ConfigMap.Name, isOptional(projection.ConfigMap.Optional))
+				}
+				if projection.Secret != nil {
+					iter("Secret", &projection.Secret.Name, isOptional(projection.Secret.Optional))
+				}
+			}
+		}
+	}
+
+	rangeOverContainers(spec, func(container *corev1.Container) {
+		for n := range container.EnvFrom {
+			source := &container.EnvFrom[n]
+			if source.ConfigMapRef != nil {
+				iter("ConfigMap", &source.ConfigMapRef.Name, isOptional(source.ConfigMapRef.Optional))
+			}
+			if source.SecretRef != nil {
+				iter("Secret", &source.SecretRef.Name, isOptional(source.SecretRef.Optional))
+			}
+		}
+		for n := range container.Env {
+			source := container.Env[n].ValueFrom
+			if source == nil {
+				continue
+			}
+			if source.ConfigMapKeyRef != nil {
+				iter("ConfigMap", &source.ConfigMapKeyRef.Name, isOptional(source.ConfigMapKeyRef.Optional))
+			}
+			if source.SecretKeyRef != nil {
+				iter("Secret", &source.SecretKeyRef.Name, isOptional(source.SecretKeyRef.Optional))
+			}
+		}
+	})
+}
@@ -0,0 +1,29 @@
+package util
+
+import (
+	"fmt"
+	"io"
+	"path"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DumpToWriter writes object to w the same way DumpListToFiles would write it to a file with
+// the given name, the format is determined by the extension, i.e. YAML for ".yaml" and ".yml",
+// and JSON for ".json"; it's useful when the destination is not a file on a filesystem
+func DumpToWriter(object runtime.Object, filename string, w io.Writer) error {
+	contentType, err := contentTypeForFileName(filename)
+	if err != nil {
+		return err
+	}
+
+	data, err := encodeFile(object, contentType, path.Base(filename), DumpOptions{})
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("kubegen/util: error writing %q – %v", filename, err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package util
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ZipIndexEntry describes one of the files in an archive written by DumpListToZip
+type ZipIndexEntry struct {
+	Path      string `json:"path"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	SHA256    string `json:"sha256"`
+}
+
+// ZipIndexFileName is the name of the index file in archives written by DumpListToZip
+const ZipIndexFileName = "index.json"
+
+// DumpListToZip writes a zip archive to w, with each of the objects stored in the same
+// file DumpListToFiles would write it to, and an index that describes all of the files
+func DumpListToZip(list *metav1.List, contentType string, w io.Writer) error {
+	archive := zip.NewWriter(w)
+
+	addFile := func(name string, data []byte) error {
+		f, err := archive.Create(name)
+		if err != nil {
+			return fmt.Errorf("kubegen/util: error adding %q to archive – %v", name, err)
+		}
+		if _, err := f.Write(data); err != nil {
+			return fmt.Errorf("kubegen/util: error adding %q to archive – %v", name, err)
+		}
+		return nil
+	}
+
+	index := []ZipIndexEntry{}
+	seen := make(map[string]bool)
+	for _, item := range list.Items {
+		basename, err := FileNameFor(item.Object, contentType)
+		if err != nil {
+			return err
+		}
+		if seen[basename] {
+			return fmt.Errorf("kubegen/util: error adding %q to archive – duplicate file name", basename)
+		}
+		seen[basename] = true
+
+		data, err := encodeFile(item.Object, contentType, basename, DumpOptions{})
+		if err != nil {
+			return err
+		}
+		if err := addFile(basename, data); err != nil {
+			return err
+		}
+
+		entry := ZipIndexEntry{
+			Path:   basename,
+			Kind:   item.Object.GetObjectKind().GroupVersionKind().Kind,
+			SHA256: sha256Of(data),
+		}
+		if objectMeta, err := meta.Accessor(item.Object); err == nil {
+			entry.Name = objectMeta.GetName()
+			entry.Namespace = objectMeta.GetNamespace()
+		}
+		index = append(index, entry)
+	}
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("kubegen/util: error encoding archive index – %v", err)
+	}
+	if err := addFile(ZipIndexFileName, data); err != nil {
+		return err
+	}
+
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("kubegen/util: error writing archive – %v", err)
+	}
+	return nil
+}